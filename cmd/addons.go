@@ -8,13 +8,36 @@ import (
 	"strings"
 	"time"
 
-	"github.com/AlecAivazis/survey/v2"
 	"github.com/dustin/go-humanize"
 	"github.com/lade-io/go-lade"
 	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 )
 
+type addonInfo struct {
+	*lade.Addon
+	URI string `json:"uri"`
+}
+
+var addonColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return v.(*lade.Addon).Name }},
+	{"SERVICE", true, func(v interface{}) interface{} { return v.(*lade.Addon).Service.Name }},
+	{"PLAN", true, func(v interface{}) interface{} { return v.(*lade.Addon).PlanID }},
+	{"REGION", false, func(v interface{}) interface{} { return printRegion(v.(*lade.Addon).Region) }},
+	{"VERSION", false, func(v interface{}) interface{} { return v.(*lade.Addon).Release }},
+	{"PUBLIC", false, func(v interface{}) interface{} { return printBool(v.(*lade.Addon).Public) }},
+	{"HOSTNAME", false, func(v interface{}) interface{} { return v.(*lade.Addon).Hostname }},
+	{"PORT", false, func(v interface{}) interface{} { return v.(*lade.Addon).Port }},
+	{"CREATED", true, func(v interface{}) interface{} { return humanize.Time(v.(*lade.Addon).CreatedAt) }},
+	{"STATUS", true, func(v interface{}) interface{} { return v.(*lade.Addon).Status }},
+}
+
+var serviceColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return v.(*lade.Service).Name }},
+	{"TITLE", true, func(v interface{}) interface{} { return v.(*lade.Service).Title }},
+	{"VERSIONS", true, func(v interface{}) interface{} { return printVersions(v.(*lade.Service).Repo) }},
+}
+
 var addonsCmd = &cobra.Command{
 	Use:   "addons",
 	Short: "Manage addons",
@@ -89,23 +112,28 @@ var addonsDetachCmd = func() *cobra.Command {
 	return cmd
 }()
 
-var addonsListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List addons",
-	Args:  cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
-			return err
-		}
-		return addonsListRun(client)
-	},
-}
+var addonsListCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List addons",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			return addonsListRun(client)
+		},
+	}
+	addColumnsFlag(cmd, addonColumns)
+	return cmd
+}()
 
 var addonsLogsCmd = func() *cobra.Command {
 	var addonName string
 	var since time.Duration
-	opts := &lade.LogStreamOpts{}
+	opts := &logStreamOpts{}
+	printOpts := &logPrintOpts{}
 	cmd := &cobra.Command{
 		Use:   "logs <addon-name>",
 		Short: "Show logs from an addon",
@@ -118,15 +146,20 @@ var addonsLogsCmd = func() *cobra.Command {
 			if len(args) > 0 {
 				addonName = args[0]
 			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
 			if since > 0 {
 				opts.Since = time.Now().UTC().Add(-since)
 			}
-			return addonsLogsRun(client, opts, addonName)
+			return addonsLogsRun(client, api, opts, printOpts, addonName)
 		},
 	}
 	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Follow Log")
 	cmd.Flags().DurationVarP(&since, "since", "s", 0, "Show Logs Since")
 	cmd.Flags().IntVarP(&opts.Tail, "tail", "t", 0, "Number of Lines")
+	addLogPrintFlags(cmd, printOpts)
 	return cmd
 }()
 
@@ -147,18 +180,22 @@ var addonsRemoveCmd = &cobra.Command{
 	},
 }
 
-var addonsServicesCmd = &cobra.Command{
-	Use:   "services",
-	Short: "List available services",
-	Args:  cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
-			return err
-		}
-		return addonsServicesRun(client)
-	},
-}
+var addonsServicesCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "services",
+		Short: "List available services",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			return addonsServicesRun(client)
+		},
+	}
+	addColumnsFlag(cmd, serviceColumns)
+	return cmd
+}()
 
 var addonsShowCmd = &cobra.Command{
 	Use:   "show <addon-name>",
@@ -214,7 +251,7 @@ func addonsAttachRun(client *lade.Client, opts *lade.AttachmentCreateOpts, appNa
 	if err := askSelect("Addon Name:", "", client, getAddonOptions, &addonName); err != nil {
 		return err
 	}
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	addon, err := client.Addon.Get(addonName)
@@ -256,7 +293,7 @@ func addonsDetachRun(client *lade.Client, appName, addonName string) error {
 	if err := askSelect("Addon Name:", "", client, getAddonOptions, &addonName); err != nil {
 		return err
 	}
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	attachments, err := client.Attachment.List(appName, addonName)
@@ -268,11 +305,10 @@ func addonsDetachRun(client *lade.Client, appName, addonName string) error {
 		names = append(names, attachment.Name)
 	}
 	sort.Strings(names)
-	prompt := &survey.Confirm{
-		Message: "Do you really want to detach " + strings.Join(names, ", ") + "?",
+	confirm, err := askApproval("Do you really want to detach " + strings.Join(names, ", ") + "?")
+	if err != nil {
+		return err
 	}
-	confirm := false
-	survey.AskOne(prompt, &confirm, nil)
 	if confirm {
 		err = client.Attachment.Delete(appName, addonName)
 	}
@@ -284,15 +320,10 @@ func addonsListRun(client *lade.Client) error {
 	if err != nil {
 		return err
 	}
-	t := table.New("NAME", "SERVICE", "PLAN", "CREATED", "STATUS")
-	for _, addon := range addons {
-		t.AddRow(addon.Name, addon.Service.Name, addon.PlanID, humanize.Time(addon.CreatedAt), addon.Status)
-	}
-	t.Print()
-	return nil
+	return printList(addons, addonColumns)
 }
 
-func addonsLogsRun(client *lade.Client, opts *lade.LogStreamOpts, addonName string) error {
+func addonsLogsRun(client *lade.Client, api *apiClient, opts *logStreamOpts, printOpts *logPrintOpts, addonName string) error {
 	err := askSelect("Addon Name:", "", client, getAddonOptions, &addonName)
 	if err != nil {
 		return err
@@ -301,7 +332,14 @@ func addonsLogsRun(client *lade.Client, opts *lade.LogStreamOpts, addonName stri
 	if err != nil {
 		return err
 	}
-	return client.Log.AddonStream(addonName, opts, printLog)
+	printOpts.Addon = addonName
+	if printOpts.Timestamps || printOpts.UTC {
+		opts.needTime = "--timestamps"
+	}
+	if outputFormat == "jsonl" {
+		return api.streamAddonLogs(getContext(), addonName, opts, printJSONLog(printOpts))
+	}
+	return api.streamAddonLogs(getContext(), addonName, opts, printLog(printOpts))
 }
 
 func addonsRemoveRun(client *lade.Client, name string) error {
@@ -313,11 +351,10 @@ func addonsRemoveRun(client *lade.Client, name string) error {
 	if err != nil {
 		return err
 	}
-	prompt := &survey.Confirm{
-		Message: "Do you really want to remove " + addon.Name + "?",
+	confirm, err := askApproval("Do you really want to remove " + addon.Name + "?")
+	if err != nil {
+		return err
 	}
-	confirm := false
-	survey.AskOne(prompt, &confirm, nil)
 	if confirm {
 		err = client.Addon.Delete(addon)
 	}
@@ -329,13 +366,7 @@ func addonsServicesRun(client *lade.Client) error {
 	if err != nil {
 		return err
 	}
-	t := table.New("NAME", "TITLE", "VERSIONS")
-	for _, service := range services {
-		serviceTags := strings.Join(service.Repo.Tags, ", ")
-		t.AddRow(service.Name, service.Title, serviceTags)
-	}
-	t.Print()
-	return nil
+	return printList(services, serviceColumns)
 }
 
 func addonsShowRun(client *lade.Client, name string) error {
@@ -355,8 +386,8 @@ func addonsShowRun(client *lade.Client, name string) error {
 	t.AddRow("Public:", printBool(addon.Public))
 	t.AddRow("Status:", addon.Status)
 	t.AddRow("Addon URI:", getAddonURI(addon))
-	t.Print()
-	return nil
+	info := &addonInfo{Addon: addon, URI: getAddonURI(addon)}
+	return getPrinter().Print(info, t.Print)
 }
 
 func addonsUpdateRun(client *lade.Client, opts *lade.AddonUpdateOpts, name string) error {
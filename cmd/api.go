@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/lade-io/go-lade"
+)
+
+const (
+	apiVersion    = "/v1/"
+	defaultAPIURL = "https://api.lade.io"
+)
+
+// apiClient calls API endpoints that are not yet covered by go-lade.
+type apiClient struct {
+	httpClient *http.Client
+	apiURL     string
+}
+
+func getAPIClient() (*apiClient, error) {
+	httpClient, err := getHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := defaultAPIURL
+	if conf.APIURL != "" {
+		apiURL = conf.APIURL
+	}
+	return &apiClient{httpClient: httpClient, apiURL: strings.TrimSuffix(apiURL, "/") + apiVersion}, nil
+}
+
+func (a *apiClient) doCreate(path string, params, out interface{}) error {
+	return a.doRequest("POST", path, params, out)
+}
+
+func (a *apiClient) doDelete(path string) error {
+	return a.doRequest("DELETE", path, nil, nil)
+}
+
+func (a *apiClient) doForm(path string, fields map[string]string, name string, file *lade.File, out interface{}) error {
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+	go func() {
+		err := writeForm(writer, fields, name, file)
+		if err == nil {
+			err = writer.Close()
+		}
+		pipeWriter.CloseWithError(err)
+	}()
+	req, err := http.NewRequest("POST", a.apiURL+path, pipeReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return a.do(req, out)
+}
+
+func (a *apiClient) doGet(path string, out interface{}) error {
+	return a.doRequest("GET", path, nil, out)
+}
+
+func (a *apiClient) doRequest(method, path string, params, out interface{}) error {
+	var payload io.Reader
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewBuffer(data)
+	}
+	req, err := http.NewRequest(method, a.apiURL+path, payload)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return a.do(req, out)
+}
+
+func (a *apiClient) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", RootCmd.Use+"/"+RootCmd.Version)
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusOK {
+		if len(body) == 0 || out == nil {
+			return nil
+		}
+		return json.Unmarshal(body, out)
+	}
+	apierr := &lade.APIError{Status: resp.StatusCode}
+	if len(body) > 0 {
+		json.Unmarshal(body, apierr)
+	}
+	return apierr
+}
+
+func writeForm(writer *multipart.Writer, fields map[string]string, name string, file *lade.File) error {
+	for key, val := range fields {
+		if val == "" {
+			continue
+		}
+		if err := writer.WriteField(key, val); err != nil {
+			return err
+		}
+	}
+	if file == nil {
+		return nil
+	}
+	part, err := writer.CreateFormFile(name, file.Name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file.Body)
+	return err
+}
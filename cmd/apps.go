@@ -4,13 +4,27 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/AlecAivazis/survey/v2"
 	"github.com/dustin/go-humanize"
 	"github.com/lade-io/go-lade"
 	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 )
 
+type appInfo struct {
+	*lade.App
+	Processes []*lade.Process `json:"processes"`
+}
+
+var appColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return v.(*lade.App).Name }},
+	{"PLAN", true, func(v interface{}) interface{} { return v.(*lade.App).PlanID }},
+	{"REGION", false, func(v interface{}) interface{} { return printRegion(v.(*lade.App).Region) }},
+	{"HOSTNAME", false, func(v interface{}) interface{} { return v.(*lade.App).Hostname }},
+	{"OWNER", false, func(v interface{}) interface{} { return printOwner(v.(*lade.App).Owner) }},
+	{"CREATED", true, func(v interface{}) interface{} { return humanize.Time(v.(*lade.App).CreatedAt) }},
+	{"STATUS", true, func(v interface{}) interface{} { return v.(*lade.App).Status }},
+}
+
 var appsCmd = &cobra.Command{
 	Use:   "apps",
 	Short: "Manage apps",
@@ -38,18 +52,22 @@ var appsCreateCmd = func() *cobra.Command {
 	return cmd
 }()
 
-var appsListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List apps",
-	Args:  cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
-			return err
-		}
-		return appsListRun(client)
-	},
-}
+var appsListCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List apps",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			return appsListRun(client)
+		},
+	}
+	addColumnsFlag(cmd, appColumns)
+	return cmd
+}()
 
 var appsRemoveCmd = &cobra.Command{
 	Use:   "remove <app-name>",
@@ -111,16 +129,11 @@ func appsListRun(client *lade.Client) error {
 	if err != nil {
 		return err
 	}
-	t := table.New("NAME", "PLAN", "CREATED", "STATUS")
-	for _, app := range apps {
-		t.AddRow(app.Name, app.PlanID, humanize.Time(app.CreatedAt), app.Status)
-	}
-	t.Print()
-	return nil
+	return printList(apps, appColumns)
 }
 
 func appsRemoveRun(client *lade.Client, name string) error {
-	err := askSelect("App Name:", getAppName, client, getAppOptions, &name)
+	err := askApp(client, &name)
 	if err != nil {
 		return err
 	}
@@ -128,11 +141,10 @@ func appsRemoveRun(client *lade.Client, name string) error {
 	if err != nil {
 		return err
 	}
-	prompt := &survey.Confirm{
-		Message: "Do you really want to remove " + app.Name + "?",
+	confirm, err := askApproval("Do you really want to remove " + app.Name + "?")
+	if err != nil {
+		return err
 	}
-	confirm := false
-	survey.AskOne(prompt, &confirm, nil)
 	if confirm {
 		err = client.App.Delete(app)
 	}
@@ -140,7 +152,7 @@ func appsRemoveRun(client *lade.Client, name string) error {
 }
 
 func appsShowRun(client *lade.Client, name string) error {
-	err := askSelect("App Name:", getAppName, client, getAppOptions, &name)
+	err := askApp(client, &name)
 	if err != nil {
 		return err
 	}
@@ -160,8 +172,8 @@ func appsShowRun(client *lade.Client, name string) error {
 	t.AddRow("Region:", app.Region.Name)
 	t.AddRow("Status:", app.Status)
 	t.AddRow("Web URL:", app.Hostname)
-	t.Print()
-	return nil
+	info := &appInfo{App: app, Processes: processes}
+	return getPrinter().Print(info, t.Print)
 }
 
 func getAppName() string {
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/lade-io/go-lade"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+type authStatus struct {
+	LoggedIn     bool         `json:"logged_in"`
+	User         *lade.User   `json:"user"`
+	Profile      string       `json:"profile"`
+	Method       string       `json:"method"`
+	Expiry       *time.Time   `json:"expiry"`
+	RefreshToken string       `json:"refresh_token_status,omitempty"`
+	APIURL       *authSetting `json:"api_url"`
+	AuthURL      *authSetting `json:"auth_url"`
+	TokenURL     *authSetting `json:"token_url"`
+	DeviceURL    *authSetting `json:"device_url"`
+}
+
+type authSetting struct {
+	URL    string `json:"url"`
+	Source string `json:"source"`
+}
+
+func (s *authSetting) String() string {
+	return s.URL + " (" + s.Source + ")"
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage authentication",
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show authentication status",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return authStatusRun()
+	},
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the logged in user",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return whoamiRun()
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+}
+
+func authStatusRun() error {
+	status, err := getAuthStatus()
+	if err != nil {
+		return err
+	}
+	err = getPrinter().Print(status, func() {
+		t := table.New("Logged In:", printBool(status.LoggedIn))
+		if status.User != nil {
+			t.AddRow("User:", status.User.Username+" <"+status.User.Email+">")
+			t.AddRow("Region:", status.User.RegionID)
+		}
+		t.AddRow("Profile:", status.Profile)
+		t.AddRow("Method:", status.Method)
+		if status.Expiry != nil {
+			t.AddRow("Expiry:", status.Expiry.Local().Format(time.RFC1123))
+		}
+		if status.RefreshToken != "" {
+			t.AddRow("Refresh Token Status:", status.RefreshToken)
+		}
+		t.AddRow("API URL:", status.APIURL)
+		t.AddRow("Auth URL:", status.AuthURL)
+		t.AddRow("Token URL:", status.TokenURL)
+		t.AddRow("Device URL:", status.DeviceURL)
+		t.Print()
+	})
+	if err == nil && !status.LoggedIn {
+		err = errNotLoggedIn
+	}
+	return err
+}
+
+func whoamiRun() error {
+	var httpClient *http.Client
+	var err error
+	switch {
+	case conf.APIToken != "":
+		if httpClient, err = getHTTPClient(); err != nil {
+			return err
+		}
+	case conf.AccessToken != "" || conf.RefreshToken != "":
+		httpClient = newAuthClient(newTokenSource(getOAuthConfig()))
+	default:
+		return errNotLoggedIn
+	}
+	user, err := newClient(httpClient).User.Me()
+	var apiErr *lade.APIError
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized || errors.As(err, &retrieveErr) {
+		return errNotLoggedIn
+	} else if err != nil {
+		return err
+	}
+	return getPrinter().Print(user, func() {
+		fmt.Println(user.Username)
+	})
+}
+
+// getAuthStatus reports the stored credentials. The refresh token is only
+// used when the access token has expired, as any other command would, and is
+// reported as not checked otherwise.
+func getAuthStatus() (*authStatus, error) {
+	status := &authStatus{
+		Profile:   conf.ProfileName,
+		Method:    "none",
+		APIURL:    getAuthSetting("LADE_API_URL", conf.APIURL, defaultAPIURL),
+		AuthURL:   getAuthSetting("LADE_AUTH_URL", conf.AuthURL, lade.Endpoint.AuthURL),
+		TokenURL:  getAuthSetting("LADE_TOKEN_URL", conf.TokenURL, lade.Endpoint.TokenURL),
+		DeviceURL: getAuthSetting("LADE_DEVICE_URL", conf.DeviceURL, deviceAuthURL),
+	}
+	var httpClient *http.Client
+	if conf.APIToken != "" {
+		status.Method = "api token"
+		var err error
+		if httpClient, err = getHTTPClient(); err != nil {
+			return nil, err
+		}
+	} else if conf.AccessToken != "" || conf.RefreshToken != "" {
+		status.Method = "oauth"
+		if !conf.Expiry.IsZero() {
+			expiry := conf.Expiry
+			status.Expiry = &expiry
+		}
+		ts := newTokenSource(getOAuthConfig())
+		if conf.RefreshToken != "" {
+			status.RefreshToken = "not checked"
+		}
+		if !ts.token.Valid() && conf.RefreshToken != "" {
+			if _, err := ts.Token(); err != nil {
+				status.RefreshToken = "rejected"
+			} else {
+				status.RefreshToken = "accepted"
+			}
+		}
+		if ts.token.Valid() {
+			httpClient = newAuthClient(ts)
+		}
+	}
+	if httpClient == nil {
+		return status, nil
+	}
+	user, err := newClient(httpClient).User.Me()
+	var e *lade.APIError
+	if errors.As(err, &e) && e.Status == http.StatusUnauthorized {
+		return status, nil
+	} else if err != nil {
+		return nil, err
+	}
+	status.LoggedIn = true
+	status.User = user
+	return status, nil
+}
+
+func getAuthSetting(env, value, fallback string) *authSetting {
+	if _, ok := os.LookupEnv(env); ok {
+		return &authSetting{URL: value, Source: "env " + env}
+	}
+	if value != "" {
+		return &authSetting{URL: value, Source: "config"}
+	}
+	return &authSetting{URL: fallback, Source: "default"}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lade-io/lade/config"
+	"golang.org/x/oauth2"
+)
+
+func TestGetAuthStatus(t *testing.T) {
+	api := newFakeAPI(t)
+	tests := []struct {
+		name     string
+		apiToken bool
+		token    *oauth2.Token
+		method   string
+		refresh  string
+		loggedIn bool
+	}{
+		{
+			name:     "api token",
+			apiToken: true,
+			method:   "api token",
+			loggedIn: true,
+		},
+		{
+			name:     "valid access token",
+			token:    &oauth2.Token{AccessToken: "token", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)},
+			method:   "oauth",
+			refresh:  "not checked",
+			loggedIn: true,
+		},
+		{
+			name:     "expired access token",
+			token:    &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)},
+			method:   "oauth",
+			refresh:  "accepted",
+			loggedIn: true,
+		},
+		{
+			name:    "revoked refresh token",
+			token:   &oauth2.Token{AccessToken: "old", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Hour)},
+			method:  "oauth",
+			refresh: "rejected",
+		},
+		{
+			name:   "logged out",
+			method: "none",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupEnv(t, api)
+			if !tt.apiToken {
+				os.Unsetenv("LADE_API_TOKEN")
+			}
+			authClient = nil
+			if err := config.Load(conf); err != nil {
+				t.Fatal(err)
+			}
+			if tt.token != nil {
+				if err := conf.StoreToken(tt.token); err != nil {
+					t.Fatal(err)
+				}
+			}
+			status, err := getAuthStatus()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Method != tt.method || status.RefreshToken != tt.refresh || status.LoggedIn != tt.loggedIn {
+				t.Errorf("got method %q, refresh token %q, logged in %v, want %q, %q, %v",
+					status.Method, status.RefreshToken, status.LoggedIn, tt.method, tt.refresh, tt.loggedIn)
+			}
+			if tt.token != nil && !status.Expiry.Equal(tt.token.Expiry) {
+				t.Errorf("got expiry %v, want stored expiry %v", status.Expiry, tt.token.Expiry)
+			}
+		})
+	}
+}
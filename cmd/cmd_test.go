@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// fakeAPI serves the endpoints used by the tests and records every request
+// that changes state.
+type fakeAPI struct {
+	*httptest.Server
+	sync.Mutex
+	requests []string
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	api := &fakeAPI{}
+	routes := map[string]string{
+		"GET /v1/users/me":      `{"id": 1, "username": "jane", "region_id": "eu"}`,
+		"GET /v1/plans/default": `{"id": "basic"}`,
+		"GET /v1/plans/user":    `[{"id": "basic"}, {"id": "large"}]`,
+		"GET /v1/regions":       `[{"id": "us", "name": "US East"}, {"id": "eu", "name": "Europe"}]`,
+		"GET /v1/apps":          `[{"id": 1, "name": "myapp"}, {"id": 2, "name": "web"}]`,
+		"GET /v1/apps/myapp":    `{"id": 1, "name": "myapp"}`,
+		"GET /v1/apps/web":      `{"id": 2, "name": "web"}`,
+		"GET /v1/pipelines": `[{"id": 1, "name": "myapp", "stages": ["staging", "production"],
+			"apps": [{"app": "myapp-staging", "stage": "staging"}, {"app": "myapp-prod", "stage": "production"}]}]`,
+		"GET /v1/apps/myapp-staging/releases": `[{"id": 3, "version": 3, "active": true}]`,
+	}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		route := r.Method + " " + r.URL.Path
+		if r.URL.Path == "/token" {
+			r.ParseForm()
+			if r.Form.Get("grant_type") == "refresh_token" && r.Form.Get("refresh_token") == "refresh" {
+				w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "refresh_token": "refresh", "expires_in": 3600}`))
+				return
+			}
+			if r.Form.Get("username") != "jane" || r.Form.Get("password") != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_grant", "error_description": "Invalid username or password"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "refresh_token": "refresh", "expires_in": 3600}`))
+			return
+		}
+		if body, ok := routes[route]; ok {
+			w.Write([]byte(body))
+			return
+		}
+		switch r.Method {
+		case http.MethodPost, http.MethodDelete:
+			body, _ := ioutil.ReadAll(r.Body)
+			api.Lock()
+			api.requests = append(api.requests, strings.TrimSpace(route+" "+string(body)))
+			api.Unlock()
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (api *fakeAPI) reset() []string {
+	api.Lock()
+	defer api.Unlock()
+	requests := api.requests
+	api.requests = nil
+	return requests
+}
+
+// runCommand runs args with prompts answered from the answers YAML.
+func runCommand(t *testing.T, answers string, args ...string) error {
+	answersFile := filepath.Join(t.TempDir(), "answers.yml")
+	if err := ioutil.WriteFile(answersFile, []byte(answers), 0600); err != nil {
+		t.Fatal(err)
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() {
+		os.Stdout = stdout
+		devNull.Close()
+	}()
+	resetFlags(RootCmd)
+	authClient = nil
+	prompter = surveyPrompter{}
+	RootCmd.SilenceErrors = true
+	RootCmd.SetArgs(append(args, "--answers", answersFile))
+	return RootCmd.ExecuteContext(context.Background())
+}
+
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			v.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func setupEnv(t *testing.T, api *fakeAPI) {
+	t.Setenv("LADE_CONFIG", filepath.Join(t.TempDir(), "config.yml"))
+	t.Setenv("LADE_CREDENTIALS", "plaintext")
+	t.Setenv("LADE_API_URL", api.URL)
+	t.Setenv("LADE_TOKEN_URL", api.URL+"/token")
+	t.Setenv("LADE_API_TOKEN", "secret")
+}
+
+// chdirProject changes to a directory with project as its project file.
+func chdirProject(t *testing.T, project string) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, ".lade"), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+}
+
+func TestCommands(t *testing.T) {
+	api := newFakeAPI(t)
+	tests := []struct {
+		name     string
+		args     []string
+		answers  string
+		project  string
+		requests []string
+		err      string
+	}{
+		{
+			name:     "create app with answers",
+			args:     []string{"apps", "create", "myapp"},
+			answers:  "Plan: large\nRegion: US East\n",
+			requests: []string{`POST /v1/apps {"name":"myapp","plan_id":"large","region_id":"us"}`},
+		},
+		{
+			name:     "create app with defaults",
+			args:     []string{"apps", "create", "myapp"},
+			answers:  "{}",
+			requests: []string{`POST /v1/apps {"name":"myapp","plan_id":"basic","region_id":"eu"}`},
+		},
+		{
+			name:     "create app with flags",
+			args:     []string{"apps", "create", "myapp", "--plan", "basic", "--region", "us"},
+			answers:  "{}",
+			requests: []string{`POST /v1/apps {"name":"myapp","plan_id":"basic","region_id":"us"}`},
+		},
+		{
+			name:    "create app with invalid answer",
+			args:    []string{"apps", "create", "myapp"},
+			answers: "Plan: huge\n",
+			err:     "Answer for Plan must be one of basic, large",
+		},
+		{
+			name: "list apps with columns",
+			args: []string{"apps", "list", "--columns", "name,status"},
+		},
+		{
+			name: "list apps with json columns",
+			args: []string{"apps", "list", "--columns", "name", "-o", "json"},
+			err:  "Columns can only be used with table output",
+		},
+		{
+			name: "list apps with format",
+			args: []string{"apps", "list", "--format", "{{.Name}}"},
+		},
+		{
+			name: "list apps with bad format",
+			args: []string{"apps", "list", "--format", "{{.Name"},
+			err:  `Format error: template: format:1: unclosed action`,
+		},
+		{
+			name:     "remove app",
+			args:     []string{"apps", "remove"},
+			answers:  "App Name: web\nDo you really want to remove web?: yes\n",
+			requests: []string{"DELETE /v1/apps/2"},
+		},
+		{
+			name:    "keep app",
+			args:    []string{"apps", "remove", "web"},
+			answers: "Do you really want to remove web?: no\n",
+		},
+		{
+			name:     "remove linked app",
+			args:     []string{"apps", "remove"},
+			answers:  "Do you really want to remove web?: yes\n",
+			project:  "app: web\n",
+			requests: []string{"DELETE /v1/apps/2"},
+		},
+		{
+			name:    "remove app with broken project",
+			args:    []string{"apps", "remove"},
+			answers: "App Name: web\n",
+			project: "app: [web\n",
+			err:     "Project error: yaml: line 1: did not find expected ',' or ']'",
+		},
+		{
+			name: "answers with non-interactive",
+			args: []string{"apps", "list", "--non-interactive"},
+			err:  "Cannot use --answers with --non-interactive",
+		},
+		{
+			name: "answers with interactive",
+			args: []string{"apps", "list", "--non-interactive=false"},
+		},
+		{
+			name:    "promote to next stage",
+			args:    []string{"promote", "--app", "myapp-staging", "--to", "myapp-prod"},
+			answers: "Do you really want to promote myapp-staging v3 to myapp-prod?: no\n",
+		},
+		{
+			name: "promote outside pipeline",
+			args: []string{"promote", "--app", "myapp-staging", "--to", "web"},
+			err:  "App web is not in the stage after myapp-staging, use one of myapp-prod",
+		},
+		{
+			name: "promote to previous stage",
+			args: []string{"promote", "--app", "myapp-prod", "--to", "myapp-staging"},
+			err:  "No pipeline stage follows myapp-prod, use lade pipelines add to add one",
+		},
+		{
+			name: "promote to itself",
+			args: []string{"promote", "--app", "myapp-staging", "--to", "myapp-staging"},
+			err:  "Cannot promote myapp-staging to itself",
+		},
+		{
+			name:    "remove app without confirmation",
+			args:    []string{"apps", "remove", "web"},
+			answers: "{}",
+			err:     "No answer given for Do you really want to remove web?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupEnv(t, api)
+			if tt.project != "" {
+				chdirProject(t, tt.project)
+			}
+			err := runCommand(t, tt.answers, tt.args...)
+			requests := api.reset()
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(requests, tt.requests) {
+				t.Errorf("got requests %q, want %q", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	api := newFakeAPI(t)
+	tests := []struct {
+		name    string
+		answers string
+		token   string
+		err     string
+	}{
+		{
+			name:    "valid credentials",
+			answers: "Username or email: jane\nPassword: secret\n",
+			token:   "token",
+		},
+		{
+			name:    "invalid credentials",
+			answers: "Username or email: jane\nPassword: wrong\n",
+			err:     "Invalid username or password",
+		},
+		{
+			name:    "missing password",
+			answers: "Username or email: jane\n",
+			err:     "No answer given for Password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupEnv(t, api)
+			os.Unsetenv("LADE_API_TOKEN")
+			err := runCommand(t, tt.answers, "login")
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token := conf.GetToken(); token.AccessToken != tt.token {
+				t.Errorf("got token %q, want %q", token.AccessToken, tt.token)
+			}
+		})
+	}
+}
+
+func TestLoginNonInteractive(t *testing.T) {
+	defer func(cmd *cobra.Command) { activeCmd = cmd }(activeCmd)
+	defer func(v bool) { nonInteractive = v }(nonInteractive)
+	nonInteractive = true
+	tests := []struct {
+		cmd *cobra.Command
+		err string
+	}{
+		{appsListCmd, "Not logged in, run lade login or set LADE_API_TOKEN"},
+		{loginCmd, "Login needs a terminal, use --answers, --device or set LADE_API_TOKEN"},
+	}
+	for _, tt := range tests {
+		activeCmd = tt.cmd
+		_, err := loginRun(getOAuthConfig())
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got error %v, want %q", tt.cmd.Name(), err, tt.err)
+		}
+		if code := ExitCode(err); code != ExitAuthError {
+			t.Errorf("%s: got exit code %d, want %d", tt.cmd.Name(), code, ExitAuthError)
+		}
+	}
+}
@@ -1,49 +1,447 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/lade-io/go-lade"
 	"github.com/spf13/cobra"
 )
 
+type deployOptions struct {
+	AllowDirty   bool
+	BuildArgs    []string
+	BuildArgFile string
+	BuildSecrets []string
+	Detach       bool
+	Dir          string
+	DockerAuth   bool
+	Dockerfile   string
+	DryRun       bool
+	Image        string
+	Include      []string
+	Ref          string
+	Registry     string
+	Rollback     bool
+	Target       string
+	Wait         bool
+	WaitTime     time.Duration
+	WarnSize     string
+	buildArgs    map[string]string
+	buildSecrets map[string]string
+}
+
 var deployCmd = func() *cobra.Command {
 	var appName string
-	opts := &lade.ReleaseCreateOpts{}
+	opts := &deployOptions{}
 	cmd := &cobra.Command{
 		Use:   "deploy",
 		Short: "Deploy an app",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Rollback {
+				opts.Wait = true
+			}
+			if opts.Detach && opts.Wait {
+				return errors.New("Cannot use --detach with --wait")
+			}
+			if err := validateImageFlags(cmd, opts); err != nil {
+				return err
+			}
+			if err := resolveDeployDir(opts); err != nil {
+				return err
+			}
+			if err := parseBuildArgs(opts); err != nil {
+				return err
+			}
+			if opts.DryRun {
+				return deployDryRun(opts)
+			}
 			client, err := getClient()
 			if err != nil {
 				return err
 			}
-			return deployRun(client, opts, appName)
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return deployRun(client, api, opts, appName)
 		},
 	}
 	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	cmd.Flags().BoolVar(&opts.AllowDirty, "allow-dirty", false, "Allow Uncommitted Changes")
+	cmd.Flags().StringArrayVar(&opts.BuildArgs, "build-arg", nil, "Build Argument <key>=<val>")
+	cmd.Flags().StringVar(&opts.BuildArgFile, "build-arg-file", "", "Build Arguments File")
+	cmd.Flags().StringArrayVar(&opts.BuildSecrets, "build-secret", nil, "Build Secret <key>=<val>")
+	cmd.Flags().BoolVar(&opts.Detach, "detach", false, "Return Without Following Build")
+	cmd.Flags().BoolVar(&opts.Detach, "no-follow", false, "Return Without Following Build")
+	cmd.Flags().MarkHidden("no-follow")
+	cmd.Flags().StringVarP(&opts.Dir, "dir", "d", "", "Source Directory")
+	cmd.Flags().BoolVar(&opts.DockerAuth, "docker-auth", false, "Send Registry Credentials From Docker Config")
+	cmd.Flags().StringVar(&opts.Dockerfile, "dockerfile", "", "Dockerfile Path")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "List Files Without Deploying")
+	cmd.Flags().StringVar(&opts.Image, "image", "", "Prebuilt Image to Deploy")
+	cmd.Flags().StringSliceVar(&opts.Include, "include", nil, "Shared Paths to Include")
+	cmd.Flags().StringVar(&opts.Ref, "ref", "", "Git Ref to Deploy")
+	cmd.Flags().StringVar(&opts.Registry, "registry-secret", "", "Registry Credentials Secret")
+	cmd.Flags().BoolVar(&opts.Rollback, "rollback-on-failure", false, "Roll Back If Not Healthy")
+	cmd.Flags().StringVar(&opts.Target, "target", "", "Build Target Stage")
+	cmd.Flags().BoolVarP(&opts.Wait, "wait", "w", false, "Wait for Healthy Processes")
+	cmd.Flags().DurationVar(&opts.WaitTime, "wait-timeout", 5*time.Minute, "Wait Timeout")
+	cmd.Flags().StringVar(&opts.WarnSize, "warn-size", "100MB", "Warn When Archive Exceeds Size")
 	return cmd
 }()
 
-func deployRun(client *lade.Client, opts *lade.ReleaseCreateOpts, appName string) error {
-	err := askSelect("App Name:", getAppName, client, getAppOptions, &appName)
+func deployRun(client *lade.Client, api *apiClient, opts *deployOptions, appName string) error {
+	err := askAppDir(client, opts.Dir, &appName)
 	if err != nil {
 		return err
 	}
-	opts.Source, err = lade.GetTarFile()
+	createOpts := &releaseCreateOpts{
+		BuildArgs:      opts.buildArgs,
+		BuildSecrets:   opts.buildSecrets,
+		Dockerfile:     opts.Dockerfile,
+		Target:         opts.Target,
+		Image:          opts.Image,
+		RegistrySecret: opts.Registry,
+	}
+	if opts.DockerAuth {
+		if createOpts.RegistryAuth, err = getDockerAuth(opts.Image); err != nil {
+			return err
+		}
+	}
+	if opts.Image == "" {
+		if createOpts.Source, err = getSource(opts); err != nil {
+			return err
+		}
+		defer createOpts.Source.Close()
+	}
+	var previous *release
+	if opts.Rollback {
+		if previous, err = getActiveRelease(api, appName); err != nil {
+			return err
+		}
+		if previous == nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s has no active release to roll back to\n", appName)
+		}
+	}
+	release, err := api.createRelease(appName, createOpts)
 	if err != nil {
 		return err
 	}
-	release, err := client.Release.Create(appName, opts)
+	if opts.Detach {
+		return getPrinter().Print(release, func() {
+			fmt.Println(release.ID)
+		})
+	}
+	secrets := []string{}
+	for _, value := range opts.buildSecrets {
+		if value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	if err = followRelease(api, release, secrets...); err != nil {
+		return err
+	}
+	if opts.Wait {
+		err = waitHealthy(client, appName, release, opts.WaitTime)
+		if err != nil && previous != nil {
+			return rollbackDeploy(client, api, appName, previous, err)
+		} else if err != nil {
+			var deployErr *deployError
+			if opts.Rollback && errors.As(err, &deployErr) {
+				deployErr.Message += ", no active release to roll back to"
+			}
+			return err
+		}
+		fmt.Printf("Release v%d is healthy use \"%s logs -a %s -f\" to view app logs\n", release.Version, RootCmd.Use, appName)
+		return nil
+	}
+	fmt.Printf("Build finished use \"%s logs -a %s -f\" to view app logs\n", RootCmd.Use, appName)
+	return nil
+}
+
+func deployDryRun(opts *deployOptions) error {
+	src, err := getSource(opts)
 	if err != nil {
 		return err
 	}
-	logOpts := &lade.LogStreamOpts{Follow: true}
-	err = client.Log.ReleaseStream(release, logOpts, printDeployLog)
+	defer src.Close()
+	return getPrinter().Print(src, func() {
+		for _, file := range src.Files {
+			fmt.Println(file)
+		}
+		from := "no ignore file"
+		if src.Ignorefile != "" {
+			from = "using " + src.Ignorefile
+		}
+		if src.Commit != "" {
+			from = "commit " + src.Commit[:7] + ", " + from
+		}
+		fmt.Printf("\n%d files, %s compressed (%s)\n", len(src.Files), humanize.Bytes(uint64(src.Size)), from)
+	})
+}
+
+func getSource(opts *deployOptions) (*sourceArchive, error) {
+	warnSize, err := humanize.ParseBytes(opts.WarnSize)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid warn size %s", opts.WarnSize)
+	}
+	var src *sourceArchive
+	if opts.Ref != "" {
+		var dirty bool
+		if dirty, err = isGitDirty(opts.Dir, append([]string{"."}, opts.Include...)...); err != nil {
+			return nil, err
+		}
+		if dirty && !opts.AllowDirty {
+			return nil, errors.New("Working tree has uncommitted changes, use --allow-dirty to deploy anyway")
+		}
+		src, err = packRef(opts.Dir, opts.Ref, opts.Include)
+	} else {
+		// Outside a git repository there is nothing to compare with.
+		dirty, _ := isGitDirty(opts.Dir, append([]string{"."}, opts.Include...)...)
+		if dirty && !opts.AllowDirty {
+			fmt.Fprintln(os.Stderr, "Warning: working tree has uncommitted changes, use --ref HEAD to deploy the last commit")
+		}
+		src, err = packSource(opts.Dir, opts.Include)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if warnSize > 0 && uint64(src.Size) > warnSize {
+		ignorefile := src.Ignorefile
+		if ignorefile == "" {
+			ignorefile = ignoreFiles[0]
+		}
+		fmt.Fprintf(os.Stderr, "Warning: archive is %s, add large files to %s to skip them\n",
+			humanize.Bytes(uint64(src.Size)), ignorefile)
+	}
+	return src, nil
+}
+
+// resolveDeployDir applies the dir and include settings of the project file
+// when they are not given as flags. Include paths are made absolute so they
+// can be used from the source directory.
+func resolveDeployDir(opts *deployOptions) error {
+	if opts.Dir == "" {
+		project, err := loadProject(".")
+		if err != nil {
+			return err
+		}
+		opts.Dir = "."
+		if project.Dir != "" {
+			opts.Dir = project.Resolve(project.Dir)
+		}
+	}
+	info, err := os.Stat(opts.Dir)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Build finished use \"%s logs -a %s -f\" to view app logs\n", RootCmd.Use, appName)
+	if !info.IsDir() {
+		return fmt.Errorf("Source %s is not a directory", opts.Dir)
+	}
+	include := opts.Include
+	if len(include) == 0 {
+		project, err := loadProject(opts.Dir)
+		if err != nil {
+			return err
+		}
+		for _, path := range project.Include {
+			include = append(include, project.Resolve(path))
+		}
+	}
+	opts.Include = nil
+	for _, path := range include {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		opts.Include = append(opts.Include, path)
+	}
+	return nil
+}
+
+// deployLogLines is the number of build log lines kept for a deployError.
+const deployLogLines = 20
+
+type deployLog struct {
+	failure string
+	lines   []string
+	masker  *strings.Replacer
+}
+
+func (d *deployLog) handle(cancel context.CancelFunc, entry *logEntry) {
+	line := d.masker.Replace(entry.Line)
+	if entry.Source == "stderr" {
+		d.failure = line
+		cancel()
+		return
+	}
+	fmt.Println(line)
+	d.lines = append(d.lines, line)
+	if len(d.lines) > deployLogLines {
+		d.lines = d.lines[1:]
+	}
+}
+
+// followRelease prints the build log of rel with secrets masked and returns
+// a deployError when the build fails.
+func followRelease(api *apiClient, rel *release, secrets ...string) error {
+	oldnew := []string{}
+	for _, secret := range secrets {
+		oldnew = append(oldnew, secret, secretMask)
+	}
+	dlog := &deployLog{masker: strings.NewReplacer(oldnew...)}
+	logOpts := &logStreamOpts{Follow: true}
+	if err := api.streamReleaseLogs(getContext(), rel, logOpts, dlog.handle); err != nil {
+		return err
+	}
+	if dlog.failure != "" {
+		return &deployError{
+			ReleaseID: rel.ID,
+			Version:   rel.Version,
+			Stage:     stageBuild,
+			Message:   dlog.failure,
+			Lines:     dlog.lines,
+		}
+	}
+	return nil
+}
+
+// waitHealthy polls the processes of the app until all of them run the
+// desired number of replicas of rel.
+func waitHealthy(client *lade.Client, appName string, rel *release, timeout time.Duration) error {
+	var status string
+	done, err := poll(timeout, func() (bool, error) {
+		processes, err := client.Process.List(appName)
+		if err != nil {
+			return false, err
+		}
+		healthy := len(processes) > 0
+		for _, process := range processes {
+			if process.ReleaseID != 0 && process.ReleaseID != rel.ID {
+				healthy = false
+			} else if process.Count != process.Replicas {
+				healthy = false
+			}
+		}
+		if info := processInfo(processes); info != status && !healthy {
+			fmt.Println("Waiting for processes " + info)
+		}
+		status = processInfo(processes)
+		return healthy, nil
+	})
+	if err == nil && !done {
+		err = &deployError{
+			ReleaseID: rel.ID,
+			Version:   rel.Version,
+			Stage:     stageRelease,
+			Message:   fmt.Sprintf("processes not healthy after %s (%s)", timeout, status),
+		}
+	}
+	return err
+}
+
+func rollbackDeploy(client *lade.Client, api *apiClient, appName string, previous *release, cause error) error {
+	fmt.Printf("Rolling back %s to v%d\n", appName, previous.Version)
+	rel, err := api.rollbackRelease(appName, strconv.Itoa(previous.Version))
+	if err != nil {
+		return fmt.Errorf("%w, rollback failed: %v", cause, err)
+	}
+	if err = followRelease(api, rel); err != nil {
+		return fmt.Errorf("%w, rollback failed: %v", cause, err)
+	}
+	var deployErr *deployError
+	if errors.As(cause, &deployErr) {
+		deployErr.Message += fmt.Sprintf(", rolled back to v%d", previous.Version)
+	}
+	return cause
+}
+
+func getActiveRelease(api *apiClient, appName string) (*release, error) {
+	releases, err := api.listReleases(appName)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range releases {
+		if rel.Active {
+			return rel, nil
+		}
+	}
+	return nil, nil
+}
+
+// secretMask replaces the values of build secrets in build logs.
+const secretMask = "********"
+
+func parseBuildArgs(opts *deployOptions) (err error) {
+	opts.buildArgs = map[string]string{}
+	if opts.BuildArgFile != "" {
+		if opts.buildArgs, err = parseEnvFile(opts.BuildArgFile); err != nil {
+			return fmt.Errorf("Build arg file %s: %v", opts.BuildArgFile, err)
+		}
+	}
+	for _, arg := range opts.BuildArgs {
+		name, value, err := splitEnvArg(arg)
+		if err != nil {
+			return err
+		}
+		if err = validateEnvName(name); err != nil {
+			return err
+		}
+		opts.buildArgs[name] = value
+	}
+	// Secrets are mounted by the builder rather than passed as build args,
+	// which are kept in the image history.
+	opts.buildSecrets = map[string]string{}
+	for _, arg := range opts.BuildSecrets {
+		name, value, err := splitEnvArg(arg)
+		if err != nil {
+			return err
+		}
+		if err = validateEnvName(name); err != nil {
+			return err
+		}
+		if _, ok := opts.buildArgs[name]; ok {
+			return fmt.Errorf("Build secret %s is also given as a build arg", name)
+		}
+		opts.buildSecrets[name] = value
+	}
+	if opts.Dockerfile != "" && opts.Ref == "" {
+		if _, err = os.Stat(filepath.Join(opts.Dir, opts.Dockerfile)); err != nil {
+			return fmt.Errorf("Dockerfile not found %s", opts.Dockerfile)
+		}
+	}
+	return nil
+}
+
+// imageConflicts are source flags that do not apply to image deploys.
+var imageConflicts = []string{"build-arg", "build-arg-file", "build-secret", "dockerfile", "dry-run", "include", "ref", "target"}
+
+func validateImageFlags(cmd *cobra.Command, opts *deployOptions) error {
+	if opts.Image == "" {
+		if opts.DockerAuth || opts.Registry != "" {
+			return errors.New("Registry credentials require --image")
+		}
+		return nil
+	}
+	if err := validateImage(opts.Image); err != nil {
+		return err
+	}
+	if opts.DockerAuth && opts.Registry != "" {
+		return errors.New("Cannot use --docker-auth with --registry-secret")
+	}
+	for _, name := range imageConflicts {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("Cannot use --image with --%s", name)
+		}
+	}
 	return nil
 }
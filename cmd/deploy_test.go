@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseBuildArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		secrets []string
+		want    map[string]string
+		secret  map[string]string
+		err     string
+	}{
+		{
+			name:    "args and secrets",
+			args:    []string{"NODE_ENV=production"},
+			secrets: []string{"NPM_TOKEN=abc"},
+			want:    map[string]string{"NODE_ENV": "production"},
+			secret:  map[string]string{"NPM_TOKEN": "abc"},
+		},
+		{
+			name:    "secret given as arg",
+			args:    []string{"NPM_TOKEN=abc"},
+			secrets: []string{"NPM_TOKEN=abc"},
+			err:     "Build secret NPM_TOKEN is also given as a build arg",
+		},
+		{
+			name:    "invalid secret",
+			secrets: []string{"NPM_TOKEN"},
+			err:     "Argument must be declared <key>=<val>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &deployOptions{BuildArgs: tt.args, BuildSecrets: tt.secrets}
+			err := parseBuildArgs(opts)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(opts.buildArgs, tt.want) {
+				t.Errorf("got build args %v, want %v", opts.buildArgs, tt.want)
+			}
+			if !reflect.DeepEqual(opts.buildSecrets, tt.secret) {
+				t.Errorf("got build secrets %v, want %v", opts.buildSecrets, tt.secret)
+			}
+		})
+	}
+}
+
+func TestCreateReleaseSecrets(t *testing.T) {
+	fields := make(chan map[string]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		form := map[string]string{}
+		for name, values := range r.MultipartForm.Value {
+			form[name] = values[0]
+		}
+		fields <- form
+		w.Write([]byte(`{"id": 1, "version": 2}`))
+	}))
+	defer server.Close()
+	api := &apiClient{httpClient: server.Client(), apiURL: server.URL + apiVersion}
+	_, err := api.createRelease("myapp", &releaseCreateOpts{
+		BuildArgs:    map[string]string{"NODE_ENV": "production"},
+		BuildSecrets: map[string]string{"NPM_TOKEN": "abc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := <-fields
+	if got, want := form["build_args"], `{"NODE_ENV":"production"}`; got != want {
+		t.Errorf("got build_args %s, want %s", got, want)
+	}
+	if got, want := form["build_secrets"], `{"NPM_TOKEN":"abc"}`; got != want {
+		t.Errorf("got build_secrets %s, want %s", got, want)
+	}
+}
@@ -3,13 +3,25 @@ package cmd
 import (
 	"strconv"
 
-	"github.com/AlecAivazis/survey/v2"
 	"github.com/dustin/go-humanize"
 	"github.com/lade-io/go-lade"
-	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 )
 
+var diskColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return v.(*lade.Disk).Name }},
+	{"PLAN", true, func(v interface{}) interface{} { return v.(*lade.Disk).PlanID }},
+	{"PATH", true, func(v interface{}) interface{} { return v.(*lade.Disk).Path }},
+	{"CREATED", true, func(v interface{}) interface{} { return humanize.Time(v.(*lade.Disk).CreatedAt) }},
+}
+
+var diskPlanColumns = []column{
+	{"ID", true, func(v interface{}) interface{} { return v.(*lade.Plan).ID }},
+	{"DISK", true, func(v interface{}) interface{} { return v.(*lade.Plan).Disk }},
+	{"PRICE HOURLY", true, func(v interface{}) interface{} { return printPrice(v.(*lade.Plan).PriceHourly, -1) }},
+	{"PRICE MONTHLY", true, func(v interface{}) interface{} { return printPrice(v.(*lade.Plan).PriceMonthly, 2) }},
+}
+
 var disksCmd = &cobra.Command{
 	Use:   "disks",
 	Short: "Manage disks",
@@ -54,21 +66,26 @@ var disksListCmd = func() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	addColumnsFlag(cmd, diskColumns)
 	return cmd
 }()
 
-var disksPlansCmd = &cobra.Command{
-	Use:   "plans",
-	Short: "List available plans",
-	Args:  cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
-			return err
-		}
-		return disksPlansRun(client)
-	},
-}
+var disksPlansCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plans",
+		Short: "List available plans",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			return disksPlansRun(client)
+		},
+	}
+	addColumnsFlag(cmd, diskPlanColumns)
+	return cmd
+}()
 
 var disksRemoveCmd = func() *cobra.Command {
 	var appName string
@@ -124,7 +141,7 @@ func init() {
 }
 
 func disksAddRun(client *lade.Client, opts *lade.DiskCreateOpts, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	if err := askInput("Disk Name:", appName, &opts.Name, validateDiskName(client, appName)); err != nil {
@@ -141,19 +158,14 @@ func disksAddRun(client *lade.Client, opts *lade.DiskCreateOpts, appName string)
 }
 
 func disksListRun(client *lade.Client, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	disks, err := client.Disk.List(appName)
 	if err != nil {
 		return err
 	}
-	t := table.New("NAME", "PLAN", "PATH", "CREATED")
-	for _, disk := range disks {
-		t.AddRow(disk.Name, disk.PlanID, disk.Path, humanize.Time(disk.CreatedAt))
-	}
-	t.Print()
-	return nil
+	return printList(disks, diskColumns)
 }
 
 func disksPlansRun(client *lade.Client) error {
@@ -161,18 +173,11 @@ func disksPlansRun(client *lade.Client) error {
 	if err != nil {
 		return err
 	}
-	t := table.New("ID", "DISK", "PRICE HOURLY", "PRICE MONTHLY")
-	for _, plan := range plans {
-		priceHourly := printPrice(plan.PriceHourly, -1)
-		priceMonthly := printPrice(plan.PriceMonthly, 2)
-		t.AddRow(plan.ID, plan.Disk, priceHourly, priceMonthly)
-	}
-	t.Print()
-	return nil
+	return printList(plans, diskPlanColumns)
 }
 
 func disksRemoveRun(client *lade.Client, appName, diskName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	if err := askSelect("Disk Name:", "", client, getDiskOptions(appName), &diskName); err != nil {
@@ -182,11 +187,10 @@ func disksRemoveRun(client *lade.Client, appName, diskName string) error {
 	if err != nil {
 		return err
 	}
-	prompt := &survey.Confirm{
-		Message: "Do you really want to delete " + disk.Name + "?",
+	confirm, err := askApproval("Do you really want to delete " + disk.Name + "?")
+	if err != nil {
+		return err
 	}
-	confirm := false
-	survey.AskOne(prompt, &confirm, nil)
 	if confirm {
 		err = client.Disk.Delete(disk)
 	}
@@ -194,7 +198,7 @@ func disksRemoveRun(client *lade.Client, appName, diskName string) error {
 }
 
 func disksUpdateRun(client *lade.Client, opts *lade.DiskUpdateOpts, appName, diskName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	if err := askSelect("Disk Name:", "", client, getDiskOptions(appName), &diskName); err != nil {
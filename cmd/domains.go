@@ -1,9 +1,8 @@
 package cmd
 
 import (
-	"github.com/AlecAivazis/survey/v2"
+	"github.com/dustin/go-humanize"
 	"github.com/lade-io/go-lade"
-	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 )
 
@@ -49,6 +48,7 @@ var domainsListCmd = func() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	addColumnsFlag(cmd, domainColumns(""))
 	return cmd
 }()
 
@@ -81,7 +81,7 @@ func init() {
 }
 
 func domainsAddRun(client *lade.Client, opts *lade.DomainCreateOpts, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	if err := askInput("Domain Name:", "", &opts.Hostname, validateDomainName(client, appName)); err != nil {
@@ -92,7 +92,7 @@ func domainsAddRun(client *lade.Client, opts *lade.DomainCreateOpts, appName str
 }
 
 func domainsListRun(client *lade.Client, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	app, err := client.App.Get(appName)
@@ -103,16 +103,20 @@ func domainsListRun(client *lade.Client, appName string) error {
 	if err != nil {
 		return err
 	}
-	t := table.New("NAME", "TYPE", "TARGET")
-	for _, domain := range domains {
-		t.AddRow(domain.Hostname, "CNAME", app.Hostname)
+	return printList(domains, domainColumns(app.Hostname))
+}
+
+func domainColumns(target string) []column {
+	return []column{
+		{"NAME", true, func(v interface{}) interface{} { return v.(*lade.Domain).Hostname }},
+		{"TYPE", true, func(v interface{}) interface{} { return "CNAME" }},
+		{"TARGET", true, func(v interface{}) interface{} { return target }},
+		{"CREATED", false, func(v interface{}) interface{} { return humanize.Time(v.(*lade.Domain).CreatedAt) }},
 	}
-	t.Print()
-	return nil
 }
 
 func domainsRemoveRun(client *lade.Client, appName, hostname string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	if err := askSelect("Domain Name:", "", client, getDomainOptions(appName), &hostname); err != nil {
@@ -122,11 +126,10 @@ func domainsRemoveRun(client *lade.Client, appName, hostname string) error {
 	if err != nil {
 		return err
 	}
-	prompt := &survey.Confirm{
-		Message: "Do you really want to delete " + domain.Hostname + "?",
+	confirm, err := askApproval("Do you really want to delete " + domain.Hostname + "?")
+	if err != nil {
+		return err
 	}
-	confirm := false
-	survey.AskOne(prompt, &confirm, nil)
 	if confirm {
 		err = client.Domain.Delete(domain)
 	}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -103,21 +104,26 @@ func init() {
 }
 
 func envEditRun(client *lade.Client, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
+	if nonInteractive {
+		return errors.New("Env edit requires an interactive terminal, use env set or env unset")
+	}
 	envs, err := client.Env.List(appName)
 	if err != nil {
 		return err
 	}
-	editor := &survey.Editor{Message: "Env Variables:", HideDefault: true, AppendDefault: true}
+	var text string
 	envMap := map[string]string{}
 	for _, env := range envs {
-		editor.Default += env.Name + "=" + env.Value + "\n"
+		text += env.Name + "=" + env.Value + "\n"
 		envMap[env.Name] = env.Value
 	}
-	var answer string
-	survey.AskOne(editor, &answer, nil)
+	answer, err := prompter.Editor("Env Variables:", text)
+	if err != nil {
+		return err
+	}
 	answerMap, err := parseEnvAnswer(answer)
 	if err != nil {
 		return err
@@ -126,11 +132,10 @@ func envEditRun(client *lade.Client, appName string) error {
 	if len(opts.Envs) == 0 {
 		return errors.New("No edits to env variables")
 	}
-	prompt := &survey.Confirm{
-		Message: "Do you really want to edit " + strings.Join(names, ", ") + "?",
+	confirm, err := askApproval("Do you really want to edit " + strings.Join(names, ", ") + "?")
+	if err != nil {
+		return err
 	}
-	confirm := false
-	survey.AskOne(prompt, &confirm, nil)
 	if confirm {
 		_, err = client.Env.Set(appName, opts)
 	}
@@ -138,21 +143,22 @@ func envEditRun(client *lade.Client, appName string) error {
 }
 
 func envListRun(client *lade.Client, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	envs, err := client.Env.List(appName)
 	if err != nil {
 		return err
 	}
-	for _, env := range envs {
-		fmt.Println(env.Name + "=" + env.Value)
-	}
-	return nil
+	return getPrinter().Print(envs, func() {
+		for _, env := range envs {
+			fmt.Println(env.Name + "=" + env.Value)
+		}
+	})
 }
 
 func envSetRun(client *lade.Client, appName string, opts *lade.EnvSetOpts) error {
-	err := askSelect("App Name:", getAppName, client, getAppOptions, &appName)
+	err := askApp(client, &appName)
 	if err != nil {
 		return err
 	}
@@ -161,7 +167,7 @@ func envSetRun(client *lade.Client, appName string, opts *lade.EnvSetOpts) error
 }
 
 func envUnsetRun(client *lade.Client, appName string, opts *lade.EnvUnsetOpts) error {
-	err := askSelect("App Name:", getAppName, client, getAppOptions, &appName)
+	err := askApp(client, &appName)
 	if err != nil {
 		return err
 	}
@@ -182,11 +188,10 @@ func envUnsetRun(client *lade.Client, appName string, opts *lade.EnvUnsetOpts) e
 			return fmt.Errorf("Name not found %s", name)
 		}
 	}
-	prompt := &survey.Confirm{
-		Message: "Do you really want to unset " + strings.Join(opts.Names, ", ") + "?",
+	confirm, err := askApproval("Do you really want to unset " + strings.Join(opts.Names, ", ") + "?")
+	if err != nil {
+		return err
 	}
-	confirm := false
-	survey.AskOne(prompt, &confirm, nil)
 	if confirm {
 		err = client.Env.Unset(appName, opts)
 	}
@@ -230,6 +235,23 @@ func parseEnvAnswer(answer string) (map[string]string, error) {
 	return envMap, nil
 }
 
+// parseEnvFile reads KEY=VAL lines from path, skipping blank lines and
+// comments starting with #.
+func parseEnvFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return parseEnvAnswer(strings.Join(lines, "\n"))
+}
+
 func parseEnvSetArgs(args []string) (*lade.EnvSetOpts, error) {
 	opts := new(lade.EnvSetOpts)
 	for _, arg := range args {
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/lade-io/go-lade"
+	"golang.org/x/oauth2"
+)
+
+// Exit codes returned by the lade command.
+const (
+	ExitOK            = 0
+	ExitError         = 1
+	ExitBuildFailed   = 2
+	ExitReleaseFailed = 3
+	ExitAuthError     = 4
+	ExitNotFound      = 5
+	ExitNetworkError  = 6
+	ExitCanceled      = 7
+	ExitAborted       = 130
+)
+
+var (
+	errAborted     = &exitError{ExitAborted, errors.New("Aborted")}
+	errNotLoggedIn = &exitError{ExitAuthError, errors.New("Not logged in")}
+)
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// Stages of a release reported by deployError.
+const (
+	stageBuild   = "build"
+	stageRelease = "release"
+)
+
+// deployError is returned when a release fails to build or activate.
+type deployError struct {
+	ReleaseID int      `json:"release_id"`
+	Version   int      `json:"version"`
+	Stage     string   `json:"stage"`
+	Status    string   `json:"status,omitempty"`
+	Message   string   `json:"message"`
+	Lines     []string `json:"lines"`
+}
+
+func (e *deployError) Error() string {
+	msg := fmt.Sprintf("Release v%d failed", e.Version)
+	if e.Stage == stageBuild {
+		msg = fmt.Sprintf("Release v%d failed to build", e.Version)
+	} else if e.Status == releaseCanceled {
+		msg = fmt.Sprintf("Release v%d was canceled", e.Version)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// ExitCode maps an error returned by RootCmd to the exit code of the process.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *exitError
+	var deployErr *deployError
+	var apiErr *lade.APIError
+	var retrieveErr *oauth2.RetrieveError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case errors.As(err, &deployErr):
+		switch {
+		case deployErr.Stage == stageBuild:
+			return ExitBuildFailed
+		case deployErr.Status == releaseCanceled:
+			return ExitCanceled
+		}
+		return ExitReleaseFailed
+	case errors.As(err, &retrieveErr):
+		return ExitAuthError
+	case errors.As(err, &apiErr):
+		switch apiErr.Status {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ExitAuthError
+		case http.StatusNotFound:
+			return ExitNotFound
+		}
+	case errors.As(err, &netErr), errors.Is(err, syscall.ECONNREFUSED):
+		return ExitNetworkError
+	}
+	return ExitError
+}
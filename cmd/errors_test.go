@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lade-io/go-lade"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"success", nil, ExitOK},
+		{"interrupt", errAborted, ExitAborted},
+		{"internal cancel", context.Canceled, ExitError},
+		{"wrapped cancel", fmt.Errorf("stream: %w", context.Canceled), ExitError},
+		{"not logged in", errNotLoggedIn, ExitAuthError},
+		{"build failed", &deployError{Stage: stageBuild}, ExitBuildFailed},
+		{"release failed", &deployError{Stage: stageRelease, Status: "failed"}, ExitReleaseFailed},
+		{"release canceled", &deployError{Stage: stageRelease, Status: releaseCanceled}, ExitCanceled},
+		{"not found", &lade.APIError{Status: http.StatusNotFound}, ExitNotFound},
+		{"forbidden", &lade.APIError{Status: http.StatusForbidden}, ExitAuthError},
+	}
+	for _, tt := range tests {
+		if code := ExitCode(tt.err); code != tt.code {
+			t.Errorf("%s: got exit code %d, want %d", tt.name, code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/zealic/xignore"
+)
+
+type gitCommit struct {
+	SHA     string
+	Message string
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			lines := strings.SplitN(msg, "\n", 2)
+			return "", errors.New(strings.TrimPrefix(lines[0], "fatal: "))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func getGitCommit(dir, ref string) (*gitCommit, error) {
+	sha, err := runGit(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || sha == "" {
+		return nil, errors.New("Unknown git ref " + ref)
+	}
+	message, err := runGit(dir, "log", "-1", "--format=%B", sha)
+	if err != nil {
+		return nil, err
+	}
+	return &gitCommit{SHA: sha, Message: message}, nil
+}
+
+func isGitDirty(dir string, paths ...string) (bool, error) {
+	args := append([]string{"status", "--porcelain", "--"}, paths...)
+	status, err := runGit(dir, args...)
+	if err != nil {
+		return false, err
+	}
+	return status != "", nil
+}
+
+// matchGitFiles applies the ignore file found in the tree of commit at dir,
+// as matchFiles does for the working tree, and returns the files kept.
+func matchGitFiles(dir, commit string) (string, []string, error) {
+	list, err := runGit(dir, "ls-tree", "-r", "-z", "--name-only", commit)
+	if err != nil {
+		return "", nil, err
+	}
+	fs := afero.NewMemMapFs()
+	names := map[string]bool{}
+	for _, name := range strings.Split(list, "\x00") {
+		if name == "" {
+			continue
+		}
+		names[name] = true
+		if err = afero.WriteFile(fs, "/"+name, nil, 0644); err != nil {
+			return "", nil, err
+		}
+	}
+	var ignorefile string
+	for _, name := range ignoreFiles {
+		if names[name] {
+			ignorefile = name
+			break
+		}
+	}
+	if ignorefile != "" {
+		patterns, err := runGit(dir, "show", commit+":./"+ignorefile)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = afero.WriteFile(fs, "/"+ignorefile, []byte(patterns), 0644); err != nil {
+			return "", nil, err
+		}
+	}
+	result, err := xignore.NewMatcher(fs).Matches("/", ignoreOptions(ignorefile))
+	if err != nil {
+		return "", nil, err
+	}
+	files := []string{}
+	for _, file := range result.UnmatchedFiles {
+		files = append(files, filepath.ToSlash(strings.TrimPrefix(file, string(filepath.Separator))))
+	}
+	return ignorefile, files, nil
+}
+
+// writeGitArchive copies the files in keep from the tree of commit into tw
+// with names under prefix and returns them. When dir is a subdirectory of
+// the repository only that subtree is archived.
+func writeGitArchive(tw *tar.Writer, dir, commit, prefix string, keep map[string]bool) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "archive", "--format=tar", commit)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	files, err := copyTar(tw, tar.NewReader(stdout), prefix, keep)
+	if err == nil {
+		_, err = io.Copy(ioutil.Discard, stdout)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	if err = cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(strings.TrimPrefix(msg, "fatal: "))
+		}
+		return nil, err
+	}
+	return files, nil
+}
+
+func copyTar(tw *tar.Writer, reader *tar.Reader, prefix string, keep map[string]bool) ([]string, error) {
+	files := []string{}
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return files, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		if header.Typeflag != tar.TypeDir && !keep[header.Name] {
+			continue
+		}
+		header.Name = path.Join(prefix, header.Name)
+		if header.Typeflag == tar.TypeDir {
+			header.Name += "/"
+		} else {
+			files = append(files, header.Name)
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err = io.Copy(tw, reader); err != nil {
+			return nil, err
+		}
+	}
+}
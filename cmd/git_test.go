@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// newGitRepo commits files to a new repository and returns its directory.
+func newGitRepo(t *testing.T, files map[string]string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A", "--force"},
+		{"-c", "user.name=Jane", "-c", "user.email=jane@example.com", "commit", "-q", "-m", "Initial commit"},
+	} {
+		if _, err := runGit(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMatchGitFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		ignorefile string
+		want       []string
+	}{
+		{
+			name:  "no ignore file",
+			files: map[string]string{"app.js": "", "lib/util.js": ""},
+			want:  []string{"app.js", "lib/util.js"},
+		},
+		{
+			name: "ladeignore first",
+			files: map[string]string{
+				".ladeignore": "*.log\n", ".gitignore": "dist\n",
+				"app.js": "", "debug.log": "", "dist/app.js": "",
+			},
+			ignorefile: ".ladeignore",
+			want:       []string{".gitignore", ".ladeignore", "app.js", "dist/app.js"},
+		},
+		{
+			name: "dockerignore keeps Dockerfile",
+			files: map[string]string{
+				".dockerignore": "Dockerfile\ntmp\n", ".gitignore": "app.js\n",
+				"Dockerfile": "", "app.js": "", "tmp/cache": "",
+			},
+			ignorefile: ".dockerignore",
+			want:       []string{".dockerignore", ".gitignore", "Dockerfile", "app.js"},
+		},
+		{
+			name:       "gitignore",
+			files:      map[string]string{".gitignore": "*.env\n", "app.js": "", "prod.env": ""},
+			ignorefile: ".gitignore",
+			want:       []string{".gitignore", "app.js"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newGitRepo(t, tt.files)
+			// Changes after the commit must not affect the match.
+			writeFiles(t, dir, map[string]string{"new.js": "", ".ladeignore": "app.js\n"})
+			ignorefile, files, err := matchGitFiles(dir, "HEAD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(files)
+			if ignorefile != tt.ignorefile {
+				t.Errorf("got ignore file %q, want %q", ignorefile, tt.ignorefile)
+			}
+			if !reflect.DeepEqual(files, tt.want) {
+				t.Errorf("got files %q, want %q", files, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackRef(t *testing.T) {
+	dir := newGitRepo(t, map[string]string{
+		".dockerignore": "*.log\n",
+		"Dockerfile":    "FROM node\n",
+		"app.js":        "",
+		"debug.log":     "",
+		"web/index.js":  "",
+	})
+	sha, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := packSource(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	writeFiles(t, dir, map[string]string{"uncommitted.js": ""})
+	ref, err := packRef(dir, "HEAD", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ref.Close()
+	sort.Strings(src.Files)
+	sort.Strings(ref.Files)
+	if !reflect.DeepEqual(ref.Files, src.Files) {
+		t.Errorf("got ref files %q, want the clean working tree files %q", ref.Files, src.Files)
+	}
+	if ref.Ignorefile != ".dockerignore" || ref.Commit != sha || ref.Message != "Initial commit" {
+		t.Errorf("got ignore file %q, commit %q and message %q", ref.Ignorefile, ref.Commit, ref.Message)
+	}
+	if ref.Size == 0 {
+		t.Error("got an empty archive")
+	}
+	sub, err := packRef(filepath.Join(dir, "web"), "HEAD", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if want := []string{"index.js"}; !reflect.DeepEqual(sub.Files, want) {
+		t.Errorf("got subdirectory files %q, want %q", sub.Files, want)
+	}
+	if _, err = packRef(dir, "v9.9.9", nil); err == nil || err.Error() != "Unknown git ref v9.9.9" {
+		t.Errorf("got error %v for an unknown ref", err)
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/lade-io/go-lade"
+	"github.com/spf13/cobra"
+)
+
+const dockerHubRegistry = "https://index.docker.io/v1/"
+
+// validImage follows the reference grammar of docker/distribution.
+var validImage = func() *regexp.Regexp {
+	domainComponent := `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	domain := domainComponent + `(?:\.` + domainComponent + `)*(?::[0-9]+)?`
+	pathComponent := `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+	name := `(?:` + domain + `/)?` + pathComponent + `(?:/` + pathComponent + `)*`
+	tag := `[\w][\w.-]{0,127}`
+	digest := `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}`
+	return regexp.MustCompile(`^` + name + `(?::` + tag + `)?(?:@` + digest + `)?$`)
+}()
+
+type image struct {
+	Reference string `json:"reference"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type registryAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+	CredHelpers map[string]string `json:"credHelpers"`
+	CredsStore  string            `json:"credsStore"`
+}
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Manage container images",
+}
+
+var imagesPushCmd = func() *cobra.Command {
+	var appName string
+	cmd := &cobra.Command{
+		Use:   "push <image-tarball>",
+		Short: "Push a saved image to the platform registry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return imagesPushRun(client, api, appName, args[0])
+		},
+	}
+	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	return cmd
+}()
+
+func init() {
+	imagesCmd.AddCommand(imagesPushCmd)
+}
+
+func (a *apiClient) pushImage(appID string, file *lade.File) (img *image, err error) {
+	img = new(image)
+	err = a.doForm("apps/"+appID+"/images", nil, "image", file, img)
+	return
+}
+
+func imagesPushRun(client *lade.Client, api *apiClient, appName, path string) error {
+	if err := askApp(client, &appName); err != nil {
+		return err
+	}
+	file := &lade.File{Body: os.Stdin, Name: "image.tar"}
+	if path != "-" {
+		input, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		file = &lade.File{Body: input, Name: filepath.Base(path)}
+	}
+	img, err := api.pushImage(appName, file)
+	if err != nil {
+		return err
+	}
+	return getPrinter().Print(img, func() {
+		fmt.Printf("Pushed %s (%s)\n", img.Reference, humanize.Bytes(uint64(img.Size)))
+		fmt.Printf("Deploy it with \"%s deploy -a %s --image %s\"\n", RootCmd.Use, appName, img.Reference)
+	})
+}
+
+func validateImage(ref string) error {
+	if !validImage.MatchString(ref) {
+		return errors.New("Image must be declared [<registry>/]<repo>[:<tag>][@<digest>]")
+	}
+	return nil
+}
+
+// getImageRegistry returns the registry host of ref as used in the auths
+// section of docker config.json.
+func getImageRegistry(ref string) string {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+	return dockerHubRegistry
+}
+
+// getDockerAuth reads the credentials for the registry of ref from the docker
+// config.json, running the configured credential helper when needed.
+func getDockerAuth(ref string) (*registryAuth, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+	config := new(dockerConfig)
+	if err = json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("Docker config error: %v", err)
+	}
+	registry := getImageRegistry(ref)
+	if entry, ok := config.Auths[registry]; ok && entry.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("Docker config error: %v", err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("Docker config error: invalid auth for " + registry)
+		}
+		return &registryAuth{Username: parts[0], Password: parts[1]}, nil
+	}
+	helper := config.CredHelpers[registry]
+	if helper == "" {
+		helper = config.CredsStore
+	}
+	if helper == "" {
+		return nil, fmt.Errorf("No docker credentials found for %s", registry)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(registry)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("Credential helper %s: %s", helper, strings.TrimSpace(stdout.String()+stderr.String()))
+	}
+	creds := struct {
+		Username string
+		Secret   string
+	}{}
+	if err = json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, fmt.Errorf("Credential helper %s: %v", helper, err)
+	}
+	return &registryAuth{Username: creds.Username, Password: creds.Secret}, nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import "testing"
+
+func TestValidateImage(t *testing.T) {
+	tests := []struct {
+		ref   string
+		valid bool
+	}{
+		{"nginx", true},
+		{"nginx:1.25-alpine", true},
+		{"my__app", true},
+		{"my.app_name---web", true},
+		{"library/nginx:latest", true},
+		{"localhost:5000/team/app:v1", true},
+		{"Registry.Example.com/app", true},
+		{"ghcr.io/org/app@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"ghcr.io/org/app:v2@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"MyApp", false},
+		{"my___app", false},
+		{"my..app", false},
+		{"-app", false},
+		{"app:", false},
+		{"app:-tag", false},
+		{"app@sha256:xyz", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		err := validateImage(tt.ref)
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("validateImage(%q) = %v, want valid %v", tt.ref, err, tt.valid)
+		}
+		if want := "Image must be declared [<registry>/]<repo>[:<tag>][@<digest>]"; err != nil && err.Error() != want {
+			t.Errorf("validateImage(%q) = %v, want %q", tt.ref, err, want)
+		}
+	}
+}
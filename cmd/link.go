@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/lade-io/go-lade"
+	"github.com/lade-io/lade/config"
+	"github.com/spf13/cobra"
+)
+
+var linkCmd = &cobra.Command{
+	Use:   "link <app-name>",
+	Short: "Link current directory to an app",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return linkRun(client, name)
+	},
+}
+
+var unlinkCmd = &cobra.Command{
+	Use:   "unlink",
+	Short: "Unlink current directory from an app",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return unlinkRun()
+	},
+}
+
+func linkRun(client *lade.Client, name string) error {
+	err := askSelect("App Name:", getAppName, client, getAppOptions, &name)
+	if err != nil {
+		return err
+	}
+	app, err := client.App.Get(name)
+	if err != nil {
+		return err
+	}
+	project, err := loadProject(".")
+	if err != nil {
+		return err
+	}
+	project.App = app.Name
+	if err = project.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Linked %s to %s\n", project.Path, app.Name)
+	return nil
+}
+
+func unlinkRun() error {
+	project, err := loadProject(".")
+	if err != nil {
+		return err
+	}
+	if project.App == "" {
+		fmt.Println("Not linked to an app")
+		return nil
+	}
+	name := project.App
+	project.App = ""
+	if project.IsEmpty() {
+		err = project.Remove()
+	} else {
+		err = project.Save()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Unlinked %s from %s\n", project.Path, name)
+	return nil
+}
+
+// getLinkedApp returns the app linked to dir, or "" when there is no project
+// file. Errors reading the project file are returned.
+func getLinkedApp(dir string) (string, error) {
+	project, err := loadProject(dir)
+	if err != nil {
+		return "", err
+	}
+	return project.App, nil
+}
+
+func loadProject(dir string) (*config.Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadProject(dir)
+}
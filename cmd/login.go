@@ -1,9 +1,14 @@
 package cmd
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/lade-io/go-lade"
@@ -11,45 +16,217 @@ import (
 	"golang.org/x/oauth2"
 )
 
-var loginCmd = &cobra.Command{
-	Use:   "login",
-	Short: "Login to your Lade account",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		oauthConf := getOAuthConfig()
-		_, err := loginRun(oauthConf)
-		return err
-	},
-}
+var loginCmd = func() *cobra.Command {
+	var device, web bool
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Login to your Lade account",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oauthConf := getOAuthConfig()
+			var err error
+			switch {
+			case device && web:
+				err = errors.New("Login can use either --device or --web")
+			case device:
+				_, err = deviceLoginRun(oauthConf)
+			case web:
+				_, err = webLoginRun(oauthConf)
+			default:
+				_, err = loginRun(oauthConf)
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&device, "device", false, "Login with a code on another device")
+	cmd.Flags().BoolVar(&web, "web", false, "Login with a web browser")
+	return cmd
+}()
 
 type loginOpts struct {
 	Username, Password string
 }
 
+type deviceAuth struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type deviceToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+func deviceLoginRun(oauthConf *oauth2.Config) (*oauth2.Token, error) {
+	v := url.Values{}
+	v.Set("client_id", oauthConf.ClientID)
+	v.Set("scope", strings.Join(oauthConf.Scopes, " "))
+	auth := new(deviceAuth)
+	if err := postOAuthForm(getDeviceAuthURL(), v, auth); err != nil {
+		return nil, err
+	}
+	verifyURL := auth.VerificationURIComplete
+	if verifyURL == "" {
+		verifyURL = auth.VerificationURI
+	}
+	fmt.Println("Open " + verifyURL + " on any device and enter code " + auth.UserCode)
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+	v = url.Values{}
+	v.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	v.Set("device_code", auth.DeviceCode)
+	v.Set("client_id", oauthConf.ClientID)
+	for auth.ExpiresIn == 0 || time.Now().Before(deadline) {
+		select {
+		case <-getContext().Done():
+			return nil, getContext().Err()
+		case <-time.After(interval):
+		}
+		resp := new(deviceToken)
+		err := postOAuthForm(oauthConf.Endpoint.TokenURL, v, resp)
+		var e *lade.APIError
+		if errors.As(err, &e) {
+			switch e.Type {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			case "access_denied":
+				return nil, errors.New("Login was denied")
+			case "expired_token":
+				return nil, errors.New("Login code has expired")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		token := &oauth2.Token{
+			AccessToken:  resp.AccessToken,
+			TokenType:    resp.TokenType,
+			RefreshToken: resp.RefreshToken,
+		}
+		if resp.ExpiresIn > 0 {
+			token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+		}
+		return storeLogin(token)
+	}
+	return nil, errors.New("Login code has expired")
+}
+
+func webLoginRun(oauthConf *oauth2.Config) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	webConf := *oauthConf
+	webConf.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr())
+	verifier, challenge, err := newPKCE()
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	authURL := webConf.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+	// Only the first callback with a matching state is used. Requests with
+	// another state are not from this login and are rejected without ending it.
+	callback := make(chan url.Values, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "Login state does not match.", http.StatusBadRequest)
+			return
+		}
+		select {
+		case callback <- query:
+		default:
+		}
+		if query.Get("error") != "" {
+			http.Error(w, "Login failed, please return to the terminal.", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, "Login successful, you can close this window.")
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+	fmt.Println("Open " + authURL + " in your browser to login")
+	openBrowser(authURL)
+	select {
+	case query := <-callback:
+		if query.Get("error") != "" {
+			return nil, &lade.APIError{Type: query.Get("error"), Message: query.Get("error_description")}
+		}
+		token, err := webConf.Exchange(getContext(), query.Get("code"),
+			oauth2.SetAuthURLParam("code_verifier", verifier),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return storeLogin(token)
+	case <-getContext().Done():
+		return nil, getContext().Err()
+	case <-time.After(5 * time.Minute):
+		return nil, errors.New("Login timed out")
+	}
+}
+
+func storeLogin(token *oauth2.Token) (*oauth2.Token, error) {
+	if err := conf.StoreToken(token); err != nil {
+		return nil, err
+	}
+	fmt.Println("Login successful")
+	return token, nil
+}
+
 func loginRun(oauthConf *oauth2.Config) (*oauth2.Token, error) {
-	opts := &loginOpts{}
-	ctx := context.Background()
-	qs := []*survey.Question{
-		{
-			Name:     "username",
-			Prompt:   &survey.Input{Message: "Username or email:"},
-			Validate: survey.Required,
-		},
-		{
-			Name:     "password",
-			Prompt:   &survey.Password{Message: "Password:"},
-			Validate: survey.Required,
-		},
+	if nonInteractive {
+		msg := "Not logged in, run lade login or set LADE_API_TOKEN"
+		if activeCmd != nil && activeCmd.Name() == "login" {
+			msg = "Login needs a terminal, use --answers, --device or set LADE_API_TOKEN"
+		}
+		return nil, &exitError{ExitAuthError, errors.New(msg)}
 	}
+	opts := &loginOpts{}
+	ctx := getContext()
 	for {
 		fmt.Println("Enter your Lade credentials:")
-		if err := survey.Ask(qs, opts); err != nil {
-			return nil, askError(err)
+		var err error
+		opts.Username, err = prompter.Input("Username or email:", "", survey.Required)
+		if err != nil {
+			return nil, err
+		}
+		opts.Password, err = prompter.Password("Password:", survey.Required)
+		if err != nil {
+			return nil, err
 		}
 		token, err := oauthConf.PasswordCredentialsToken(ctx, opts.Username, opts.Password)
 		if err != nil {
 			if oautherr, ok := err.(*oauth2.RetrieveError); ok {
 				apierr := &lade.APIError{}
 				if err = json.Unmarshal(oautherr.Body, apierr); err == nil {
+					if !prompter.Interactive() {
+						return nil, apierr
+					}
 					switch apierr.Type {
 					case "invalid_grant":
 						fmt.Println("Invalid username or password. Please try again.")
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lade-io/lade/config"
+	"golang.org/x/oauth2"
+)
+
+// oauthServer stands in for the Lade OAuth server. The token endpoint waits
+// for ready so that callbacks can be made while the login is in progress.
+type oauthServer struct {
+	*httptest.Server
+	sync.Mutex
+	challenge string
+	ready     chan struct{}
+}
+
+func newOAuthServer(t *testing.T) *oauthServer {
+	s := &oauthServer{ready: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device/code":
+			fmt.Fprint(w, `{"device_code": "device", "user_code": "ABCD", "verification_uri": "https://lade.io/device", "interval": 1}`)
+		case "/oauth/token":
+			<-s.ready
+			sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+			s.Lock()
+			challenge := s.challenge
+			s.Unlock()
+			switch {
+			case r.Form.Get("code") == "code" && challenge == base64.RawURLEncoding.EncodeToString(sum[:]):
+			case r.Form.Get("device_code") == "device":
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid_grant"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *oauthServer) config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID: "lade",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   s.URL + "/oauth/authorize",
+			TokenURL:  s.URL + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// authorize does the browser's part of the login and returns the code.
+func (s *oauthServer) authorize(query url.Values) string {
+	s.Lock()
+	defer s.Unlock()
+	s.challenge = query.Get("code_challenge")
+	return "code"
+}
+
+func loadTestConfig(t *testing.T) {
+	t.Setenv("LADE_CONFIG", filepath.Join(t.TempDir(), "config.yml"))
+	t.Setenv("LADE_CREDENTIALS", "plaintext")
+	if err := config.Load(conf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebLogin(t *testing.T) {
+	loadTestConfig(t)
+	server := newOAuthServer(t)
+	statuses := make(chan []int, 1)
+	defer func(open func(string) error) { openBrowser = open }(openBrowser)
+	openBrowser = func(target string) error {
+		authURL, err := url.Parse(target)
+		if err != nil {
+			return err
+		}
+		query := authURL.Query()
+		code := server.authorize(query)
+		state := query.Get("state")
+		// A request from another login comes first and repeated callbacks
+		// follow, none of which may end or block the login.
+		requests := []url.Values{
+			{"state": {"other"}, "code": {"stolen"}},
+			{"state": {state}, "code": {code}},
+			{"state": {state}, "code": {code}},
+			{"state": {state}, "code": {code}},
+		}
+		go func() {
+			defer close(server.ready)
+			client := &http.Client{Timeout: 2 * time.Second}
+			var codes []int
+			for _, v := range requests {
+				resp, err := client.Get(query.Get("redirect_uri") + "?" + v.Encode())
+				if err != nil {
+					codes = append(codes, 0)
+					continue
+				}
+				resp.Body.Close()
+				codes = append(codes, resp.StatusCode)
+			}
+			statuses <- codes
+		}()
+		return nil
+	}
+	token, err := webLoginRun(server.config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token" || conf.GetToken().AccessToken != "token" {
+		t.Errorf("got token %q, stored %q", token.AccessToken, conf.GetToken().AccessToken)
+	}
+	want := []int{http.StatusBadRequest, http.StatusOK, http.StatusOK, http.StatusOK}
+	if got := <-statuses; !reflect.DeepEqual(got, want) {
+		t.Errorf("got callback statuses %v, want %v", got, want)
+	}
+}
+
+func TestDeviceLogin(t *testing.T) {
+	server := newOAuthServer(t)
+	close(server.ready)
+	t.Setenv("LADE_DEVICE_URL", server.URL+"/device/code")
+	loadTestConfig(t)
+	token, err := deviceLoginRun(server.config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token" || conf.GetToken().AccessToken != "token" {
+		t.Errorf("got token %q, stored %q", token.AccessToken, conf.GetToken().AccessToken)
+	}
+}
@@ -1,16 +1,130 @@
 package cmd
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/lade-io/go-lade"
 	"github.com/spf13/cobra"
 )
 
+var logLevels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+var levelAliases = map[string]string{
+	"alert":       "fatal",
+	"crit":        "fatal",
+	"critical":    "fatal",
+	"dbg":         "debug",
+	"emerg":       "fatal",
+	"emergency":   "fatal",
+	"err":         "error",
+	"information": "info",
+	"notice":      "info",
+	"panic":       "fatal",
+	"trc":         "trace",
+	"warning":     "warn",
+}
+
+var (
+	levelKeys   = []string{"level", "lvl", "severity", "log.level"}
+	logfmtLevel = regexp.MustCompile(`(?:^|\s)(?:level|lvl|severity)="?([a-zA-Z]+)`)
+)
+
+const logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+var logTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+type logsOptions struct {
+	All       bool
+	Apps      []string
+	Exclude   string
+	Grep      string
+	Level     string
+	Processes []string
+	Since     string
+	Until     string
+}
+
+// logPrintOpts controls how log entries are printed.
+type logPrintOpts struct {
+	Addon      string
+	ShowApp    bool
+	Timestamps bool
+	UTC        bool
+}
+
+// logRecord is a log entry as printed by --output jsonl.
+type logRecord struct {
+	App       string     `json:"app,omitempty"`
+	Addon     string     `json:"addon,omitempty"`
+	Process   string     `json:"process"`
+	Container string     `json:"container"`
+	Source    string     `json:"source"`
+	Time      *time.Time `json:"time,omitempty"`
+	Line      string     `json:"line"`
+}
+
+type logExportOpts struct {
+	Gzip       bool
+	MaxSize    string
+	Out        string
+	PerProcess bool
+	maxSize    int64
+}
+
+// logExport writes log entries to a single jsonl file or to a text file per
+// process, starting a new file when the next entry would take one past the
+// max size before compression.
+type logExport struct {
+	App   string   `json:"app"`
+	Files []string `json:"files"`
+	Lines int      `json:"lines"`
+	opts  *logExportOpts
+	mu    sync.Mutex
+	files map[string]*logFile
+}
+
+type logFile struct {
+	file    *os.File
+	counter *countWriter
+	gzip    *gzip.Writer
+}
+
+// logFlushInterval is how often a followed export flushes compressed files.
+const logFlushInterval = 5 * time.Second
+
+// logFilter drops log entries that do not match the logs flags.
+type logFilter struct {
+	processes []string
+	grep      *regexp.Regexp
+	exclude   *regexp.Regexp
+	level     int
+	until     time.Time
+}
+
 var logsCmd = func() *cobra.Command {
-	var appName string
-	var since time.Duration
-	opts := &lade.LogStreamOpts{}
+	opts := &logsOptions{}
+	printOpts := &logPrintOpts{}
+	streamOpts := &logStreamOpts{}
 	cmd := &cobra.Command{
 		Use:   "logs",
 		Short: "Show logs from an app",
@@ -20,33 +134,499 @@ var logsCmd = func() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if since > 0 {
-				opts.Since = time.Now().UTC().Add(-since)
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			if opts.All && len(opts.Apps) > 0 {
+				return errors.New("Cannot use --all with --app")
+			}
+			filter, err := getLogFilter(opts, streamOpts)
+			if err != nil {
+				return err
+			}
+			return logsRun(client, api, streamOpts, printOpts, filter, opts)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Show Logs From All Apps")
+	cmd.Flags().StringSliceVarP(&opts.Apps, "app", "a", nil, "App Name")
+	cmd.Flags().StringVar(&opts.Exclude, "exclude", "", "Hide Lines Matching Regex")
+	cmd.Flags().BoolVarP(&streamOpts.Follow, "follow", "f", false, "Follow Log")
+	cmd.Flags().StringVar(&opts.Grep, "grep", "", "Show Lines Matching Regex")
+	cmd.Flags().StringVar(&opts.Level, "level", "", "Minimum Log Level")
+	cmd.Flags().StringSliceVarP(&opts.Processes, "process", "p", nil, "Process Types or Names")
+	cmd.Flags().StringVarP(&opts.Since, "since", "s", "", "Show Logs Since")
+	cmd.Flags().IntVarP(&streamOpts.Tail, "tail", "t", 0, "Number of Lines")
+	cmd.Flags().StringVar(&opts.Until, "until", "", "Show Logs Until")
+	addLogPrintFlags(cmd, printOpts)
+	return cmd
+}()
+
+var logsExportCmd = func() *cobra.Command {
+	var appName string
+	opts := &logsOptions{}
+	exportOpts := &logExportOpts{}
+	streamOpts := &logStreamOpts{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export logs from an app to files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			filter, err := getLogFilter(opts, streamOpts)
+			if err != nil {
+				return err
+			}
+			maxSize, err := humanize.ParseBytes(exportOpts.MaxSize)
+			if err != nil {
+				return fmt.Errorf("Invalid max size %s", exportOpts.MaxSize)
 			}
-			return logsRun(client, opts, appName)
+			exportOpts.maxSize = int64(maxSize)
+			return logsExportRun(client, api, streamOpts, exportOpts, filter, appName)
 		},
 	}
 	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
-	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Follow Log")
-	cmd.Flags().DurationVarP(&since, "since", "s", 0, "Show Logs Since")
-	cmd.Flags().IntVarP(&opts.Tail, "tail", "t", 0, "Number of Lines")
+	cmd.Flags().BoolVarP(&streamOpts.Follow, "follow", "f", false, "Follow Log")
+	cmd.Flags().BoolVar(&exportOpts.Gzip, "gzip", true, "Compress Files")
+	cmd.Flags().StringVar(&exportOpts.MaxSize, "max-size", "100MB", "Rotate Files After Size")
+	cmd.Flags().StringVar(&exportOpts.Out, "out", ".", "Output Directory")
+	cmd.Flags().BoolVar(&exportOpts.PerProcess, "per-process", false, "Write One File per Process")
+	cmd.Flags().StringSliceVarP(&opts.Processes, "process", "p", nil, "Process Types or Names")
+	cmd.Flags().StringVarP(&opts.Since, "since", "s", "", "Show Logs Since")
+	cmd.Flags().StringVar(&opts.Until, "until", "", "Show Logs Until")
 	return cmd
 }()
 
-func logsRun(client *lade.Client, opts *lade.LogStreamOpts, appName string) error {
-	err := askSelect("App Name:", getAppName, client, getAppOptions, &appName)
+func init() {
+	logsCmd.AddCommand(logsExportCmd)
+}
+
+func addLogPrintFlags(cmd *cobra.Command, opts *logPrintOpts) {
+	cmd.Flags().BoolVar(&opts.Timestamps, "timestamps", false, "Show Timestamps")
+	cmd.Flags().BoolVar(&opts.UTC, "utc", false, "Show Times in UTC")
+}
+
+func logsRun(client *lade.Client, api *apiClient, opts *logStreamOpts, printOpts *logPrintOpts, filter *logFilter, logsOpts *logsOptions) error {
+	appNames, err := getLogApps(client, logsOpts)
 	if err != nil {
 		return err
 	}
-	processes, err := client.Process.List(appName)
+	printOpts.ShowApp = len(appNames) > 1
+	switch {
+	case printOpts.Timestamps || printOpts.UTC:
+		opts.needTime = "--timestamps"
+	case !filter.until.IsZero():
+		opts.needTime = "--until"
+	case printOpts.ShowApp:
+		opts.needTime = "merging logs from several apps"
+	}
+	var width int
+	replicas := map[string]int{}
+	for _, appName := range appNames {
+		processes, err := client.Process.List(appName)
+		if err != nil {
+			return err
+		}
+		for _, process := range processes {
+			if n, ok := replicas[process.Type]; !ok || process.Replicas > n {
+				replicas[process.Type] = process.Replicas
+			}
+			size := len(process.Type)
+			if printOpts.ShowApp {
+				size += len(appName) + 1
+			}
+			if size > width {
+				width = size
+			}
+		}
+	}
+	if err := validateProcesses(filter.processes, replicas); err != nil {
+		return err
+	}
+	print := printNameLog(width, printOpts)
+	if outputFormat == "jsonl" {
+		print = printJSONLog(printOpts)
+	}
+	if len(appNames) == 1 {
+		return api.streamAppLogs(getContext(), appNames[0], opts, filterLog(appNames[0], filter, print))
+	}
+	merger := newLogMerger(logReorderWindow, print)
+	defer merger.Close()
+	return streamAll(appNames, func(ctx context.Context, appName string) error {
+		return api.streamAppLogs(ctx, appName, opts, filterLog(appName, filter, merger.Add))
+	})
+}
+
+// validateProcesses checks that each name is a process type or a type and
+// instance number, like web.1, within the replicas of that type.
+func validateProcesses(names []string, replicas map[string]int) error {
+	for _, name := range names {
+		parts := strings.SplitN(name, ".", 2)
+		count, ok := replicas[parts[0]]
+		if !ok {
+			return fmt.Errorf("Process %s not found", name)
+		}
+		if len(parts) == 2 {
+			number, err := strconv.Atoi(parts[1])
+			if err != nil || number < 1 {
+				return fmt.Errorf("Process %s must be declared <type>[.<number>]", name)
+			}
+			if number > count {
+				return fmt.Errorf("Process %s not found, %s has %d instances", name, parts[0], count)
+			}
+		}
+	}
+	return nil
+}
+
+// filterLog tags entries with appName and passes those matching filter on.
+func filterLog(appName string, filter *logFilter, handler logHandler) logHandler {
+	return func(cancel context.CancelFunc, entry *logEntry) {
+		entry.App = appName
+		if filter.match(entry) {
+			handler(cancel, entry)
+		}
+	}
+}
+
+func getLogApps(client *lade.Client, opts *logsOptions) ([]string, error) {
+	if opts.All {
+		apps, err := client.App.List()
+		if err != nil {
+			return nil, err
+		}
+		if len(apps) == 0 {
+			return nil, errors.New("You have not created any apps")
+		}
+		names := []string{}
+		for _, app := range apps {
+			names = append(names, app.Name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	if len(opts.Apps) > 0 {
+		return opts.Apps, nil
+	}
+	var appName string
+	if err := askApp(client, &appName); err != nil {
+		return nil, err
+	}
+	return []string{appName}, nil
+}
+
+func logsExportRun(client *lade.Client, api *apiClient, opts *logStreamOpts, exportOpts *logExportOpts, filter *logFilter, appName string) error {
+	if err := askApp(client, &appName); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(exportOpts.Out, 0755); err != nil {
+		return err
+	}
+	if !filter.until.IsZero() {
+		opts.needTime = "--until"
+	}
+	export := &logExport{App: appName, Files: []string{}, opts: exportOpts, files: map[string]*logFile{}}
+	stopFlush := func() {}
+	if opts.Follow {
+		stopFlush = export.flushEvery(logFlushInterval)
+	}
+	var writeErr error
+	err := api.streamAppLogs(getContext(), appName, opts, filterLog(appName, filter, func(cancel context.CancelFunc, entry *logEntry) {
+		if writeErr = export.write(entry); writeErr != nil {
+			cancel()
+		}
+	}))
+	stopFlush()
+	if closeErr := export.Close(); err == nil {
+		err = closeErr
+	}
+	if writeErr != nil {
+		return writeErr
+	}
 	if err != nil {
 		return err
 	}
-	var width int
-	for _, process := range processes {
-		if len(process.Type) > width {
-			width = len(process.Type)
+	return getPrinter().Print(export, func() {
+		fmt.Printf("Exported %d lines from %s\n", export.Lines, appName)
+		for _, name := range export.Files {
+			fmt.Println(name)
+		}
+	})
+}
+
+func (o *logPrintOpts) localTime(t time.Time) time.Time {
+	if o.UTC {
+		return t.UTC()
+	}
+	return t.Local()
+}
+
+// timestamp returns the time prefix of entry when timestamps are shown.
+func (o *logPrintOpts) timestamp(entry *logEntry) string {
+	if !o.Timestamps && !o.UTC {
+		return ""
+	}
+	return o.localTime(entry.Time).Format(logTimeFormat) + " "
+}
+
+func (e *logExport) write(entry *logEntry) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var key, ext string
+	var data []byte
+	process := strings.SplitN(entry.Name, ".", 2)[0]
+	if e.opts.PerProcess {
+		key, ext = process, ".log"
+		line := entry.Name + " " + entry.Line + "\n"
+		if !entry.Time.IsZero() {
+			line = entry.Time.UTC().Format(time.RFC3339Nano) + " " + line
+		}
+		data = []byte(line)
+	} else {
+		ext = ".jsonl"
+		record := &logRecord{App: e.App, Process: process, Container: entry.Name, Source: entry.Source, Line: entry.Line}
+		if !entry.Time.IsZero() {
+			t := entry.Time.UTC()
+			record.Time = &t
+		}
+		var err error
+		if data, err = json.Marshal(record); err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	}
+	f := e.files[key]
+	if f != nil && e.opts.maxSize > 0 && f.counter.n > 0 && f.counter.n+int64(len(data)) > e.opts.maxSize {
+		if err := f.Close(); err != nil {
+			return err
+		}
+		f = nil
+	}
+	if f == nil {
+		prefix := e.App
+		if key != "" {
+			prefix += "-" + key
+		}
+		var err error
+		if f, err = e.create(prefix, ext); err != nil {
+			return err
+		}
+		e.files[key] = f
+	}
+	if _, err := f.counter.Write(data); err != nil {
+		return err
+	}
+	e.Lines++
+	return nil
+}
+
+// create opens a new file named after prefix and the current time.
+func (e *logExport) create(prefix, ext string) (*logFile, error) {
+	if e.opts.Gzip {
+		ext += ".gz"
+	}
+	base := filepath.Join(e.opts.Out, prefix+"-"+time.Now().UTC().Format("20060102T150405Z"))
+	name := base + ext
+	for i := 1; ; i++ {
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			name = fmt.Sprintf("%s-%d%s", base, i, ext)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.Files = append(e.Files, name)
+		f := &logFile{file: file, counter: &countWriter{Writer: file}}
+		if e.opts.Gzip {
+			f.gzip = gzip.NewWriter(file)
+			f.counter.Writer = f.gzip
+		}
+		return f, nil
+	}
+}
+
+// flushEvery flushes the files every interval until stop is called, so that
+// a followed export can be read while it runs.
+func (e *logExport) flushEvery(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.Flush()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// Flush writes out the data held by the gzip writers. A failed flush leaves
+// the writer in error, so the next write reports it.
+func (e *logExport) Flush() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, f := range e.files {
+		if f.gzip != nil {
+			f.gzip.Flush()
+		}
+	}
+}
+
+func (e *logExport) Close() (err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, f := range e.files {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
+
+func (f *logFile) Close() error {
+	if f.gzip != nil {
+		if err := f.gzip.Close(); err != nil {
+			f.file.Close()
+			return err
+		}
+	}
+	return f.file.Close()
+}
+
+func getLogFilter(opts *logsOptions, streamOpts *logStreamOpts) (filter *logFilter, err error) {
+	now := time.Now()
+	if streamOpts.Since, err = parseLogTime(opts.Since, now); err != nil {
+		return nil, err
+	}
+	if streamOpts.Until, err = parseLogTime(opts.Until, now); err != nil {
+		return nil, err
+	}
+	if !streamOpts.Until.IsZero() {
+		if streamOpts.Follow {
+			return nil, errors.New("Cannot use --follow with --until")
+		}
+		if streamOpts.Until.Before(streamOpts.Since) {
+			return nil, errors.New("Time given by --until must be after --since")
+		}
+	}
+	filter = &logFilter{processes: opts.Processes, level: -1, until: streamOpts.Until}
+	if opts.Grep != "" {
+		if filter.grep, err = regexp.Compile(opts.Grep); err != nil {
+			return nil, fmt.Errorf("Invalid --grep: %v", err)
+		}
+	}
+	if opts.Exclude != "" {
+		if filter.exclude, err = regexp.Compile(opts.Exclude); err != nil {
+			return nil, fmt.Errorf("Invalid --exclude: %v", err)
+		}
+	}
+	if opts.Level != "" {
+		if filter.level = parseLevel(opts.Level); filter.level < 0 {
+			return nil, fmt.Errorf("Level must be one of %s", strings.Join(logLevels, ", "))
+		}
+	}
+	return filter, nil
+}
+
+func (f *logFilter) match(entry *logEntry) bool {
+	if len(f.processes) > 0 {
+		processType := strings.SplitN(entry.Name, ".", 2)[0]
+		if !contains(f.processes, entry.Name) && !contains(f.processes, processType) {
+			return false
+		}
+	}
+	if !f.until.IsZero() && entry.Time.After(f.until) {
+		return false
+	}
+	if f.grep != nil && !f.grep.MatchString(entry.Line) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(entry.Line) {
+		return false
+	}
+	if f.level < 0 {
+		return true
+	}
+	// Lines without a level, such as stack traces and plain text output,
+	// are kept so that errors are not hidden.
+	level := detectLevel(entry.Line)
+	return level < 0 || level >= f.level
+}
+
+// detectLevel returns the index in logLevels of the level field of a JSON or
+// logfmt line, or -1 when the line has none.
+func detectLevel(line string) int {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		fields := map[string]interface{}{}
+		if json.Unmarshal([]byte(line), &fields) == nil {
+			for _, key := range levelKeys {
+				switch value := fields[key].(type) {
+				case string:
+					return parseLevel(value)
+				case float64:
+					// Numeric levels as written by pino and bunyan.
+					index := int(value)/10 - 1
+					if index < 0 {
+						index = 0
+					} else if index >= len(logLevels) {
+						index = len(logLevels) - 1
+					}
+					return index
+				}
+			}
+			return -1
+		}
+	}
+	if match := logfmtLevel.FindStringSubmatch(line); match != nil {
+		return parseLevel(match[1])
+	}
+	return -1
+}
+
+func parseLevel(name string) int {
+	name = strings.ToLower(name)
+	if alias, ok := levelAliases[name]; ok {
+		name = alias
+	}
+	for i, level := range logLevels {
+		if level == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseLogTime accepts a duration before now, like 1h, or a local timestamp.
+func parseLogTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d).UTC(), nil
+	}
+	for _, layout := range logTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t.UTC(), nil
 		}
 	}
-	return client.Log.AppStream(appName, opts, printNameLog(width))
+	return time.Time{}, fmt.Errorf("Invalid time %s, use a duration like 1h or a time like 2006-01-02T15:04", value)
 }
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lade-io/go-lade"
+	"github.com/spf13/cobra"
+)
+
+func TestLogFilterLevel(t *testing.T) {
+	filter := &logFilter{level: parseLevel("warn")}
+	tests := []struct {
+		line string
+		keep bool
+	}{
+		{`{"level": "error", "msg": "failed"}`, true},
+		{`{"level": "info", "msg": "started"}`, false},
+		{`level=warning msg="slow query"`, true},
+		{`level=debug msg="cache hit"`, false},
+		{`{"level": 50, "msg": "pino error"}`, true},
+		{`    at Server.handle (/app/server.js:12:5)`, true},
+		{`{"msg": "no level"}`, true},
+	}
+	for _, tt := range tests {
+		if keep := filter.match(&logEntry{LogEntry: lade.LogEntry{Name: "web.1", Line: tt.line}}); keep != tt.keep {
+			t.Errorf("match(%q) = %v, want %v", tt.line, keep, tt.keep)
+		}
+	}
+}
+
+func TestDetectLevel(t *testing.T) {
+	tests := []struct {
+		line  string
+		level string
+	}{
+		{`{"level": "info", "msg": "started"}`, "info"},
+		{`{"lvl": "WARN"}`, "warn"},
+		{`{"severity": "CRITICAL"}`, "fatal"},
+		{`{"log.level": "err"}`, "error"},
+		{`{"level": 10}`, "trace"},
+		{`{"level": 30}`, "info"},
+		{`{"level": 60}`, "fatal"},
+		{`{"level": 99}`, "fatal"},
+		{`{"level": 0}`, "trace"},
+		{`  {"msg": "no level"}`, ""},
+		{`{"level": "verbose"}`, ""},
+		{`time=2026-10-01 level=debug msg="cache hit"`, "debug"},
+		{`lvl="notice" msg=ready`, "info"},
+		{`severity=alert`, "fatal"},
+		{`clevel=error`, ""},
+		{`{broken json level=error`, "error"},
+		{`GET /health 200`, ""},
+	}
+	for _, tt := range tests {
+		want := parseLevel(tt.level)
+		if got := detectLevel(tt.line); got != want {
+			t.Errorf("detectLevel(%q) = %d, want %d (%s)", tt.line, got, want, tt.level)
+		}
+	}
+}
+
+func TestParseLogTime(t *testing.T) {
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("CEST", 2*60*60)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{value: ""},
+		{value: "1h", want: time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC)},
+		{value: "90m", want: time.Date(2026, 10, 1, 10, 30, 0, 0, time.UTC)},
+		{value: "2026-10-01T10:00:00Z", want: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "2026-10-01T10:00:00+01:00", want: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		{value: "2026-10-01T10:00:00", want: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)},
+		{value: "2026-10-01T10:00", want: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)},
+		{value: "2026-10-01 10:00:30", want: time.Date(2026, 10, 1, 8, 0, 30, 0, time.UTC)},
+		{value: "2026-10-01 10:00", want: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)},
+		{value: "2026-10-01", want: time.Date(2026, 9, 30, 22, 0, 0, 0, time.UTC)},
+		{value: "yesterday", err: true},
+		{value: "10:00", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseLogTime(tt.value, now)
+		if tt.err {
+			want := "Invalid time " + tt.value + ", use a duration like 1h or a time like 2006-01-02T15:04"
+			if err == nil || err.Error() != want {
+				t.Errorf("parseLogTime(%q) got error %v, want %q", tt.value, err, want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseLogTime(%q) got error %v", tt.value, err)
+		} else if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("parseLogTime(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValidateProcesses(t *testing.T) {
+	replicas := map[string]int{"web": 2, "worker": 1}
+	tests := []struct {
+		names []string
+		err   string
+	}{
+		{names: []string{"web", "worker.1", "web.2"}},
+		{names: []string{"cron"}, err: "Process cron not found"},
+		{names: []string{"web.9"}, err: "Process web.9 not found, web has 2 instances"},
+		{names: []string{"web.abc"}, err: "Process web.abc must be declared <type>[.<number>]"},
+		{names: []string{"web.0"}, err: "Process web.0 must be declared <type>[.<number>]"},
+		{names: []string{"web."}, err: "Process web. must be declared <type>[.<number>]"},
+	}
+	for _, tt := range tests {
+		err := validateProcesses(tt.names, replicas)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("validateProcesses(%q) = %v", tt.names, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("validateProcesses(%q) = %v, want %q", tt.names, err, tt.err)
+		}
+	}
+}
+
+func TestLogsAppRequired(t *testing.T) {
+	defer func(cmd *cobra.Command) { activeCmd = cmd }(activeCmd)
+	defer func(v bool) { nonInteractive = v }(nonInteractive)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	activeCmd = logsCmd
+	nonInteractive = true
+	_, err = getLogApps(nil, &logsOptions{})
+	if want := "App Name is required, use --app to set it"; err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+func TestLogMerger(t *testing.T) {
+	start := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	var got []string
+	merger := newLogMerger(time.Hour, func(cancel context.CancelFunc, entry *logEntry) {
+		got = append(got, entry.App+"/"+entry.Line)
+	})
+	entries := []struct {
+		app    string
+		line   string
+		offset time.Duration
+	}{
+		{"api", "second", 2 * time.Second},
+		{"api", "fourth", 4 * time.Second},
+		{"web", "first", time.Second},
+		{"web", "third", 3 * time.Second},
+		{"worker", "also third", 3 * time.Second},
+	}
+	for _, e := range entries {
+		entry := &logEntry{App: e.app, Time: start.Add(e.offset)}
+		entry.Line = e.line
+		merger.Add(nil, entry)
+	}
+	merger.mu.Lock()
+	held := len(got)
+	merger.mu.Unlock()
+	if held != 0 {
+		t.Fatalf("got %d entries printed within the reorder window", held)
+	}
+	merger.Close()
+	want := []string{"web/first", "api/second", "web/third", "worker/also third", "api/fourth"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got entries %q, want %q", got, want)
+	}
+}
+
+// readExport returns the lines of each exported file by process prefix.
+func readExport(t *testing.T, files []string) map[string][][]string {
+	t.Helper()
+	got := map[string][][]string{}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var r io.Reader = f
+		if strings.HasSuffix(name, ".gz") {
+			if r, err = gzip.NewReader(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		lines := []string{}
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			lines = append(lines, fields[len(fields)-1])
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+		prefix := strings.SplitN(filepath.Base(name), "-", 3)[1]
+		got[prefix] = append(got[prefix], lines)
+	}
+	return got
+}
+
+func TestLogExport(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		opts := &logExportOpts{Gzip: compress, Out: t.TempDir(), PerProcess: true, maxSize: 30}
+		export := &logExport{App: "myapp", opts: opts, files: map[string]*logFile{}}
+		for i, name := range []string{"web.1", "worker.1", "web.2", "web.1", "worker.1", "web.2"} {
+			entry := &logEntry{}
+			entry.Name = name
+			entry.Line = "line" + string(rune('a'+i))
+			if err := export.write(entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := export.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// Each line takes 12 bytes, so two fit in 30 bytes and a third
+		// starts a new file.
+		want := map[string][][]string{
+			"web":    {{"linea", "linec"}, {"lined", "linef"}},
+			"worker": {{"lineb", "linee"}},
+		}
+		if got := readExport(t, export.Files); !reflect.DeepEqual(got, want) {
+			t.Errorf("gzip %v: got files %q, want %q", compress, got, want)
+		}
+		if export.Lines != 6 {
+			t.Errorf("gzip %v: got %d lines, want 6", compress, export.Lines)
+		}
+		for _, name := range export.Files {
+			if info, err := os.Stat(name); err != nil || !compress && info.Size() > opts.maxSize {
+				t.Errorf("gzip %v: %s is larger than the max size", compress, name)
+			}
+		}
+	}
+}
+
+func TestLogExportFlush(t *testing.T) {
+	opts := &logExportOpts{Gzip: true, Out: t.TempDir()}
+	export := &logExport{App: "myapp", opts: opts, files: map[string]*logFile{}}
+	defer export.Close()
+	stop := export.flushEvery(10 * time.Millisecond)
+	entry := &logEntry{}
+	entry.Name = "web.1"
+	entry.Line = "started"
+	if err := export.write(entry); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	stop()
+	f, err := os.Open(export.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, _ := bufio.NewReader(r).ReadString('\n')
+	if !strings.Contains(line, `"line":"started"`) {
+		t.Errorf("got %q on disk before close, want the flushed entry", line)
+	}
+}
@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
-	"os"
-	"os/signal"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
 	"strings"
 	"sync"
 	"syscall"
@@ -14,6 +20,10 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// deviceAuthURL is the device authorization endpoint that accompanies
+// lade.Endpoint.
+var deviceAuthURL = defaultAPIURL + "/login/oauth2/device"
+
 func getOAuthConfig() *oauth2.Config {
 	oauth := &oauth2.Config{
 		ClientID: lade.DefaultClientID,
@@ -29,6 +39,68 @@ func getOAuthConfig() *oauth2.Config {
 	return oauth
 }
 
+func getDeviceAuthURL() string {
+	if conf.DeviceURL != "" {
+		return conf.DeviceURL
+	}
+	return deviceAuthURL
+}
+
+func newPKCE() (string, string, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+var openBrowser = func(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
+
+func postOAuthForm(target string, v url.Values, out interface{}) error {
+	req, err := http.NewRequest("POST", target, strings.NewReader(v.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(lade.DefaultClientID, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		apierr := &lade.APIError{Status: resp.StatusCode}
+		if err = json.NewDecoder(resp.Body).Decode(apierr); err != nil || apierr.Type == "" {
+			return lade.ErrServerError
+		}
+		return apierr
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func randomString(size int) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+var authClient *http.Client
+
 type tokenSource struct {
 	*sync.Mutex
 	oauthConf *oauth2.Config
@@ -36,16 +108,8 @@ type tokenSource struct {
 }
 
 func newTokenSource(oauthConf *oauth2.Config) *tokenSource {
-	mutex := new(sync.Mutex)
-	stopChan := make(chan os.Signal, 1)
-	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-stopChan
-		mutex.Lock()
-		os.Exit(0)
-	}()
 	return &tokenSource{
-		Mutex:     mutex,
+		Mutex:     new(sync.Mutex),
 		oauthConf: oauthConf,
 		token:     conf.GetToken(),
 	}
@@ -64,7 +128,18 @@ func (t *tokenSource) Token() (*oauth2.Token, error) {
 	if t.token.Valid() {
 		return t.token, nil
 	}
-	ts := t.oauthConf.TokenSource(context.Background(), t.token)
+	return t.refresh()
+}
+
+func (t *tokenSource) Refresh() (*oauth2.Token, error) {
+	t.Lock()
+	defer t.Unlock()
+	return t.refresh()
+}
+
+func (t *tokenSource) refresh() (*oauth2.Token, error) {
+	expired := &oauth2.Token{RefreshToken: t.token.RefreshToken}
+	ts := t.oauthConf.TokenSource(context.Background(), expired)
 	token, err := ts.Token()
 	if err != nil {
 		return nil, err
@@ -74,6 +149,30 @@ func (t *tokenSource) Token() (*oauth2.Token, error) {
 }
 
 func getClient() (*lade.Client, error) {
+	httpClient, err := getHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return newClient(httpClient), nil
+}
+
+func newClient(httpClient *http.Client) *lade.Client {
+	client := lade.NewClient(httpClient)
+	if conf.APIURL != "" {
+		client.SetAPIURL(conf.APIURL)
+	}
+	return client
+}
+
+func getHTTPClient() (*http.Client, error) {
+	if authClient != nil {
+		return authClient, nil
+	}
+	if conf.APIToken != "" {
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: conf.APIToken})
+		authClient = newAuthClient(ts)
+		return authClient, nil
+	}
 	oauthConf := getOAuthConfig()
 	ts := newTokenSource(oauthConf)
 	if !ts.token.Valid() {
@@ -91,10 +190,27 @@ func getClient() (*lade.Client, error) {
 			}
 		}
 	}
-	httpClient := oauth2.NewClient(context.Background(), ts)
-	client := lade.NewClient(httpClient)
-	if conf.APIURL != "" {
-		client.SetAPIURL(conf.APIURL)
+	authClient = newAuthClient(ts)
+	return authClient, nil
+}
+
+// newAuthClient returns a client that authorizes requests with ts and aborts
+// them when the command is interrupted.
+func newAuthClient(ts oauth2.TokenSource) *http.Client {
+	client := oauth2.NewClient(context.Background(), ts)
+	client.Transport = &contextTransport{base: client.Transport}
+	return client
+}
+
+// contextTransport binds requests made without a context to the context of
+// the running command.
+type contextTransport struct {
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context() == context.Background() {
+		req = req.WithContext(getContext())
 	}
-	return client, nil
+	return t.base.RoundTrip(req)
 }
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/lade-io/go-lade"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	outputColumns  []string
+	outputFormat   string
+	outputTemplate string
+	formatTemplate *template.Template
+)
+
+var printers = map[string]printer{
+	"json":  jsonPrinter{},
+	"jsonl": jsonlPrinter{},
+	"table": tablePrinter{},
+	"yaml":  yamlPrinter{},
+}
+
+type printer interface {
+	Print(data interface{}, render func()) error
+}
+
+type column struct {
+	Name    string
+	Default bool
+	Value   func(interface{}) interface{}
+}
+
+type jsonPrinter struct{}
+
+func (jsonPrinter) Print(data interface{}, render func()) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+type jsonlPrinter struct{}
+
+func (jsonlPrinter) Print(data interface{}, render func()) error {
+	enc := json.NewEncoder(os.Stdout)
+	v := getValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return enc.Encode(data)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := enc.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tablePrinter struct{}
+
+func (tablePrinter) Print(data interface{}, render func()) error {
+	render()
+	return nil
+}
+
+type templatePrinter struct {
+	tmpl *template.Template
+}
+
+func (p templatePrinter) Print(data interface{}, render func()) error {
+	v := getValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return p.execute(data)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := p.execute(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p templatePrinter) execute(item interface{}) error {
+	if err := p.tmpl.Execute(os.Stdout, item); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+type yamlPrinter struct{}
+
+func (yamlPrinter) Print(data interface{}, render func()) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	node := new(yaml.Node)
+	if err = yaml.Unmarshal(body, node); err != nil {
+		return err
+	}
+	resetStyle(node)
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err = enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func addColumnsFlag(cmd *cobra.Command, columns []column) {
+	names := []string{}
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	usage := "Table columns (" + strings.Join(names, ", ") + ")"
+	cmd.Flags().StringSliceVar(&outputColumns, "columns", nil, usage)
+}
+
+func getPrinter() printer {
+	if formatTemplate != nil {
+		return templatePrinter{tmpl: formatTemplate}
+	}
+	return printers[outputFormat]
+}
+
+func printList(items interface{}, columns []column) error {
+	selected, err := selectColumns(columns)
+	if err != nil {
+		return err
+	}
+	return getPrinter().Print(items, func() {
+		headers := []interface{}{}
+		for _, col := range selected {
+			headers = append(headers, col.Name)
+		}
+		t := table.New(headers...)
+		v := getValueOf(items)
+		for i := 0; i < v.Len(); i++ {
+			row := []interface{}{}
+			for _, col := range selected {
+				row = append(row, col.Value(v.Index(i).Interface()))
+			}
+			t.AddRow(row...)
+		}
+		t.Print()
+	})
+}
+
+func printOwner(owner *lade.User) string {
+	if owner == nil {
+		return ""
+	}
+	return owner.Email
+}
+
+func printRegion(region *lade.Region) string {
+	if region == nil {
+		return ""
+	}
+	return region.Name
+}
+
+func printVersions(repo *lade.Repo) string {
+	if repo == nil {
+		return ""
+	}
+	return strings.Join(repo.Tags, ", ")
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+func selectColumns(columns []column) ([]column, error) {
+	if len(outputColumns) == 0 {
+		selected := []column{}
+		for _, col := range columns {
+			if col.Default {
+				selected = append(selected, col)
+			}
+		}
+		return selected, nil
+	}
+	colMap := map[string]column{}
+	for _, col := range columns {
+		colMap[col.Name] = col
+	}
+	selected := []column{}
+	for _, name := range outputColumns {
+		col, ok := colMap[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("Column not found %s", name)
+		}
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+// validateOutput checks the output flags and parses the --format template
+// used by getPrinter.
+func validateOutput() error {
+	formatTemplate = nil
+	if _, ok := printers[outputFormat]; !ok {
+		return errors.New("Output must be one of json, jsonl, table or yaml")
+	}
+	if len(outputColumns) > 0 && (outputFormat != "table" || outputTemplate != "") {
+		return errors.New("Columns can only be used with table output")
+	}
+	if outputTemplate == "" {
+		return nil
+	}
+	if outputFormat != "table" {
+		return errors.New("Format cannot be combined with json, jsonl or yaml output")
+	}
+	tmpl, err := template.New("format").Parse(outputTemplate)
+	if err != nil {
+		return fmt.Errorf("Format error: %s", err)
+	}
+	formatTemplate = tmpl
+	return nil
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/iancoleman/orderedmap"
+	"github.com/lade-io/go-lade"
+	"github.com/spf13/cobra"
+)
+
+type pipeline struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	Stages    []string       `json:"stages"`
+	Apps      []*pipelineApp `json:"apps"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type pipelineApp struct {
+	App   string `json:"app"`
+	Stage string `json:"stage"`
+}
+
+type pipelineCreateOpts struct {
+	Name   string   `json:"name"`
+	Stages []string `json:"stages"`
+}
+
+var pipelineColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return v.(*pipeline).Name }},
+	{"ID", false, func(v interface{}) interface{} { return v.(*pipeline).ID }},
+	{"STAGES", true, func(v interface{}) interface{} { return printStages(v.(*pipeline)) }},
+	{"CREATED", true, func(v interface{}) interface{} { return humanize.Time(v.(*pipeline).CreatedAt) }},
+}
+
+var defaultStages = []string{"staging", "production"}
+
+var pipelinesCmd = &cobra.Command{
+	Use:   "pipelines",
+	Short: "Manage release pipelines",
+}
+
+var pipelinesAddCmd = func() *cobra.Command {
+	opts := &pipelineApp{}
+	cmd := &cobra.Command{
+		Use:   "add <pipeline-name>",
+		Short: "Add an app to a pipeline stage",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return pipelinesAddRun(client, api, name, opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.App, "app", "a", "", "App Name")
+	cmd.Flags().StringVarP(&opts.Stage, "stage", "s", "", "Stage")
+	return cmd
+}()
+
+var pipelinesCreateCmd = func() *cobra.Command {
+	opts := &pipelineCreateOpts{}
+	cmd := &cobra.Command{
+		Use:   "create <pipeline-name>",
+		Short: "Create a pipeline",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				opts.Name = args[0]
+			}
+			return pipelinesCreateRun(api, opts)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&opts.Stages, "stages", "s", defaultStages, "Ordered Stages")
+	return cmd
+}()
+
+var pipelinesListCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List pipelines",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return pipelinesListRun(api)
+		},
+	}
+	addColumnsFlag(cmd, pipelineColumns)
+	return cmd
+}()
+
+func init() {
+	pipelinesCmd.AddCommand(pipelinesAddCmd)
+	pipelinesCmd.AddCommand(pipelinesCreateCmd)
+	pipelinesCmd.AddCommand(pipelinesListCmd)
+}
+
+func (a *apiClient) addPipelineApp(name string, opts *pipelineApp) (p *pipeline, err error) {
+	p = new(pipeline)
+	err = a.doCreate("pipelines/"+name+"/apps", opts, p)
+	return
+}
+
+func (a *apiClient) createPipeline(opts *pipelineCreateOpts) (p *pipeline, err error) {
+	p = new(pipeline)
+	err = a.doCreate("pipelines", opts, p)
+	return
+}
+
+func (a *apiClient) getPipeline(name string) (p *pipeline, err error) {
+	p = new(pipeline)
+	err = a.doGet("pipelines/"+name, p)
+	return
+}
+
+func (a *apiClient) listPipelines() (pipelines []*pipeline, err error) {
+	err = a.doGet("pipelines", &pipelines)
+	return
+}
+
+func pipelinesAddRun(client *lade.Client, api *apiClient, name string, opts *pipelineApp) error {
+	if err := askSelect("Pipeline Name:", "", client, getPipelineOptions(api), &name); err != nil {
+		return err
+	}
+	p, err := api.getPipeline(name)
+	if err != nil {
+		return err
+	}
+	if err = askApp(client, &opts.App); err != nil {
+		return err
+	}
+	if err = askSelect("Stage:", "", client, getStageOptions(p), &opts.Stage); err != nil {
+		return err
+	}
+	if !contains(p.Stages, opts.Stage) {
+		return fmt.Errorf("Stage must be one of %s", strings.Join(p.Stages, ", "))
+	}
+	p, err = api.addPipelineApp(p.Name, opts)
+	if err != nil {
+		return err
+	}
+	return getPrinter().Print(p, func() {
+		fmt.Printf("Added %s to %s stage %s\n", opts.App, p.Name, opts.Stage)
+	})
+}
+
+func pipelinesCreateRun(api *apiClient, opts *pipelineCreateOpts) error {
+	if err := askInput("Pipeline Name:", "", &opts.Name, validateName); err != nil {
+		return err
+	}
+	if len(opts.Stages) == 0 {
+		return errors.New("Pipeline must have at least one stage")
+	}
+	for i, stage := range opts.Stages {
+		if err := validateName(stage); err != nil {
+			return fmt.Errorf("Stage %s: %v", stage, err)
+		}
+		if contains(opts.Stages[:i], stage) {
+			return fmt.Errorf("Stage %s is listed twice", stage)
+		}
+	}
+	p, err := api.createPipeline(opts)
+	if err != nil {
+		return err
+	}
+	return getPrinter().Print(p, func() {
+		fmt.Printf("Created %s with stages %s\n", p.Name, strings.Join(p.Stages, ", "))
+	})
+}
+
+func pipelinesListRun(api *apiClient) error {
+	pipelines, err := api.listPipelines()
+	if err != nil {
+		return err
+	}
+	return printList(pipelines, pipelineColumns)
+}
+
+// nextStageApps returns the apps in the stage after the one holding appName.
+func (p *pipeline) nextStageApps(appName string) []string {
+	var stage string
+	for _, app := range p.Apps {
+		if app.App == appName {
+			stage = app.Stage
+		}
+	}
+	apps := []string{}
+	for i, name := range p.Stages {
+		if name != stage || i+1 == len(p.Stages) {
+			continue
+		}
+		for _, app := range p.Apps {
+			if app.Stage == p.Stages[i+1] {
+				apps = append(apps, app.App)
+			}
+		}
+	}
+	return apps
+}
+
+func getPipelineOptions(api *apiClient) optionsFunc {
+	return func(client *lade.Client) (*orderedmap.OrderedMap, error) {
+		pipelines, err := api.listPipelines()
+		if err != nil {
+			return nil, err
+		}
+		if len(pipelines) == 0 {
+			return nil, errors.New("You have not created any pipelines")
+		}
+		options := orderedmap.New()
+		for _, p := range pipelines {
+			options.Set(p.Name, p.Name)
+		}
+		options.SortKeys(sort.Strings)
+		return options, nil
+	}
+}
+
+func getStageOptions(p *pipeline) optionsFunc {
+	return func(client *lade.Client) (*orderedmap.OrderedMap, error) {
+		options := orderedmap.New()
+		for _, stage := range p.Stages {
+			options.Set(stage, stage)
+		}
+		return options, nil
+	}
+}
+
+func printStages(p *pipeline) string {
+	stages := []string{}
+	for _, stage := range p.Stages {
+		apps := []string{}
+		for _, app := range p.Apps {
+			if app.Stage == stage {
+				apps = append(apps, app.App)
+			}
+		}
+		if len(apps) == 0 {
+			apps = append(apps, "-")
+		}
+		stages = append(stages, stage+": "+strings.Join(apps, ", "))
+	}
+	return strings.Join(stages, " -> ")
+}
@@ -2,34 +2,39 @@ package cmd
 
 import (
 	"github.com/lade-io/go-lade"
-	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 )
 
-var plansCmd = &cobra.Command{
-	Use:   "plans",
-	Short: "List available plans",
-	Args:  cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
-			return err
-		}
-		return plansRun(client)
-	},
+var planColumns = []column{
+	{"ID", true, func(v interface{}) interface{} { return v.(*lade.Plan).ID }},
+	{"MEMORY", true, func(v interface{}) interface{} { return v.(*lade.Plan).Ram }},
+	{"CPUS", true, func(v interface{}) interface{} { return v.(*lade.Plan).Cpu }},
+	{"DISK", true, func(v interface{}) interface{} { return v.(*lade.Plan).Disk }},
+	{"PRICE HOURLY", true, func(v interface{}) interface{} { return printPrice(v.(*lade.Plan).PriceHourly, -1) }},
+	{"PRICE MONTHLY", true, func(v interface{}) interface{} { return printPrice(v.(*lade.Plan).PriceMonthly, 2) }},
 }
 
+var plansCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plans",
+		Short: "List available plans",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			return plansRun(client)
+		},
+	}
+	addColumnsFlag(cmd, planColumns)
+	return cmd
+}()
+
 func plansRun(client *lade.Client) error {
 	plans, err := client.Plan.List("")
 	if err != nil {
 		return err
 	}
-	t := table.New("ID", "MEMORY", "CPUS", "DISK", "PRICE HOURLY", "PRICE MONTHLY")
-	for _, plan := range plans {
-		priceHourly := printPrice(plan.PriceHourly, -1)
-		priceMonthly := printPrice(plan.PriceMonthly, 2)
-		t.AddRow(plan.ID, plan.Ram, plan.Cpu, plan.Disk, priceHourly, priceMonthly)
-	}
-	t.Print()
-	return nil
+	return printList(plans, planColumns)
 }
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lade-io/lade/config"
+	"github.com/spf13/cobra"
+)
+
+type profileInfo struct {
+	Name     string `json:"name"`
+	Active   bool   `json:"active"`
+	APIURL   string `json:"api_url"`
+	LoggedIn bool   `json:"logged_in"`
+}
+
+var profileColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return v.(*profileInfo).Name }},
+	{"ACTIVE", true, func(v interface{}) interface{} { return printBool(v.(*profileInfo).Active) }},
+	{"API URL", true, func(v interface{}) interface{} { return v.(*profileInfo).APIURL }},
+	{"LOGGED IN", true, func(v interface{}) interface{} { return printBool(v.(*profileInfo).LoggedIn) }},
+}
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage auth profiles",
+}
+
+var profilesListCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return profilesListRun()
+		},
+	}
+	addColumnsFlag(cmd, profileColumns)
+	return cmd
+}()
+
+var profilesRemoveCmd = &cobra.Command{
+	Use:   "remove <profile-name>",
+	Short: "Remove a profile",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return profilesRemoveRun(name)
+	},
+}
+
+var profilesUseCmd = &cobra.Command{
+	Use:   "use <profile-name>",
+	Short: "Switch the default profile",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return profilesUseRun(name)
+	},
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesListCmd)
+	profilesCmd.AddCommand(profilesRemoveCmd)
+	profilesCmd.AddCommand(profilesUseCmd)
+}
+
+func profilesListRun() error {
+	profiles := []*profileInfo{}
+	for _, name := range conf.ProfileNames() {
+		token, err := conf.ProfileToken(name)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, &profileInfo{
+			Name:     name,
+			Active:   name == conf.ProfileName,
+			APIURL:   getProfileAPIURL(conf.Profiles[name]),
+			LoggedIn: token.RefreshToken != "",
+		})
+	}
+	return printList(profiles, profileColumns)
+}
+
+func profilesRemoveRun(name string) error {
+	if err := askSelect("Profile Name:", "", nil, getProfileOptions, &name); err != nil {
+		return err
+	}
+	confirm, err := askApproval("Do you really want to remove " + name + "?")
+	if err != nil {
+		return err
+	}
+	if confirm {
+		err = conf.RemoveProfile(name)
+	}
+	return err
+}
+
+func profilesUseRun(name string) error {
+	if err := askSelect("Profile Name:", conf.ProfileName, nil, getProfileOptions, &name); err != nil {
+		return err
+	}
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if err := conf.UseProfile(name); err != nil {
+		return err
+	}
+	fmt.Println("Switched to profile " + name)
+	return nil
+}
+
+func getProfileAPIURL(profile *config.Profile) string {
+	if profile.APIURL == "" {
+		return defaultAPIURL
+	}
+	return profile.APIURL
+}
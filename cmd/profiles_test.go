@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	api := newFakeAPI(t)
+	tests := []struct {
+		name    string
+		args    []string
+		answers string
+		current string
+		active  string
+		names   []string
+		err     string
+	}{
+		{
+			name:    "use profile",
+			args:    []string{"profiles", "use", "personal"},
+			current: "personal",
+			names:   []string{"personal", "work"},
+		},
+		{
+			name:    "use new profile",
+			args:    []string{"profiles", "use", "staging"},
+			current: "staging",
+			names:   []string{"personal", "staging", "work"},
+		},
+		{
+			name: "use invalid profile",
+			args: []string{"profiles", "use", "My Profile"},
+			err:  "Name must only contain a-z, 0-9 or dash (-), start with a-z, and end with a-z or 0-9",
+		},
+		{
+			name:    "remove profile",
+			args:    []string{"profiles", "remove"},
+			answers: "Profile Name: work\nDo you really want to remove work?: yes\n",
+			names:   []string{"personal"},
+		},
+		{
+			name:    "list profiles with flag",
+			args:    []string{"profiles", "list", "--profile", "personal"},
+			current: "work",
+			active:  "personal",
+			names:   []string{"personal", "work"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupEnv(t, api)
+			data := "profile: work\nprofiles:\n  personal:\n    access_token: personal\n  work:\n    access_token: work\n"
+			if err := ioutil.WriteFile(os.Getenv("LADE_CONFIG"), []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			err := runCommand(t, tt.answers, tt.args...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.active != "" && conf.ProfileName != tt.active {
+				t.Errorf("got active profile %q, want %q", conf.ProfileName, tt.active)
+			}
+			if conf.Current != tt.current {
+				t.Errorf("got current profile %q, want %q", conf.Current, tt.current)
+			}
+			if names := conf.ProfileNames(); !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("got profiles %q, want %q", names, tt.names)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/orderedmap"
+	"github.com/lade-io/go-lade"
+	"github.com/spf13/cobra"
+)
+
+type promoteOpts struct {
+	App string `json:"app"`
+}
+
+type promoteInfo struct {
+	*release
+	EnvDiff *envDiff `json:"env_diff,omitempty"`
+}
+
+// envDiff lists env names that differ between two apps, never their values.
+type envDiff struct {
+	Missing []string `json:"missing"`
+	Extra   []string `json:"extra"`
+	Changed []string `json:"changed"`
+}
+
+var promoteCmd = func() *cobra.Command {
+	var appName, target string
+	var diff bool
+	cmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Promote a release to another app",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return promoteRun(client, api, appName, target, diff)
+		},
+	}
+	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	cmd.Flags().BoolVar(&diff, "env-diff", false, "Show Env Differences")
+	cmd.Flags().StringVarP(&target, "to", "t", "", "Target App Name")
+	return cmd
+}()
+
+func (a *apiClient) promoteRelease(appID, version, target string) (rel *release, err error) {
+	rel = new(release)
+	err = a.doCreate("apps/"+appID+"/releases/"+version+"/promote", &promoteOpts{App: target}, rel)
+	return
+}
+
+func promoteRun(client *lade.Client, api *apiClient, appName, target string, diff bool) error {
+	if err := askApp(client, &appName); err != nil {
+		return err
+	}
+	options, err := getPromoteOptions(api, appName)(client)
+	if err != nil {
+		return err
+	}
+	keys := options.Keys()
+	_, ok := options.Get(target)
+	switch {
+	case target == appName:
+		return fmt.Errorf("Cannot promote %s to itself", appName)
+	case target != "" && !ok:
+		return fmt.Errorf("App %s is not in the stage after %s, use one of %s", target, appName, strings.Join(keys, ", "))
+	case target == "" && nonInteractive && len(keys) == 1:
+		target = keys[0]
+	}
+	getOptions := func(client *lade.Client) (*orderedmap.OrderedMap, error) {
+		return options, nil
+	}
+	if err = askSelect("Target App Name:", "", client, getOptions, &target); err != nil {
+		return err
+	}
+	active, err := getActiveRelease(api, appName)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		return fmt.Errorf("App %s has no active release", appName)
+	}
+	info := &promoteInfo{}
+	if diff {
+		if info.EnvDiff, err = getEnvDiff(client, appName, target); err != nil {
+			return err
+		}
+		info.EnvDiff.print(appName, target)
+	}
+	confirm, err := askApproval(fmt.Sprintf("Do you really want to promote %s v%d to %s?", appName, active.Version, target))
+	if err != nil || !confirm {
+		return err
+	}
+	info.release, err = api.promoteRelease(appName, strconv.Itoa(active.Version), target)
+	if err != nil {
+		return err
+	}
+	if err = followRelease(api, info.release); err != nil {
+		return err
+	}
+	return getPrinter().Print(info, func() {
+		fmt.Printf("Promoted %s v%d to %s as v%d\n", appName, active.Version, target, info.Version)
+	})
+}
+
+func getEnvDiff(client *lade.Client, appName, target string) (*envDiff, error) {
+	source, err := client.Env.List(appName)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := client.Env.List(target)
+	if err != nil {
+		return nil, err
+	}
+	destMap := map[string]string{}
+	for _, env := range dest {
+		destMap[env.Name] = env.Value
+	}
+	diff := &envDiff{Missing: []string{}, Extra: []string{}, Changed: []string{}}
+	for _, env := range source {
+		value, ok := destMap[env.Name]
+		if !ok {
+			diff.Missing = append(diff.Missing, env.Name)
+		} else if value != env.Value {
+			diff.Changed = append(diff.Changed, env.Name)
+		}
+		delete(destMap, env.Name)
+	}
+	for name := range destMap {
+		diff.Extra = append(diff.Extra, name)
+	}
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Extra)
+	sort.Strings(diff.Changed)
+	return diff, nil
+}
+
+func (d *envDiff) print(appName, target string) {
+	if len(d.Missing)+len(d.Extra)+len(d.Changed) == 0 {
+		fmt.Fprintf(os.Stderr, "Env names match between %s and %s\n", appName, target)
+		return
+	}
+	if len(d.Missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Only in %s: %s\n", appName, strings.Join(d.Missing, ", "))
+	}
+	if len(d.Extra) > 0 {
+		fmt.Fprintf(os.Stderr, "Only in %s: %s\n", target, strings.Join(d.Extra, ", "))
+	}
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(os.Stderr, "Different values: %s\n", strings.Join(d.Changed, ", "))
+	}
+}
+
+// getPromoteOptions offers the apps in the next pipeline stage after appName.
+func getPromoteOptions(api *apiClient, appName string) optionsFunc {
+	return func(client *lade.Client) (*orderedmap.OrderedMap, error) {
+		pipelines, err := api.listPipelines()
+		if err != nil {
+			return nil, err
+		}
+		options := orderedmap.New()
+		for _, p := range pipelines {
+			for _, app := range p.nextStageApps(appName) {
+				options.Set(app, app)
+			}
+		}
+		if len(options.Keys()) == 0 {
+			return nil, fmt.Errorf("No pipeline stage follows %s, use lade pipelines add to add one", appName)
+		}
+		options.SortKeys(sort.Strings)
+		return options, nil
+	}
+}
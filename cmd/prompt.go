@@ -2,28 +2,32 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/core"
 	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/dustin/go-humanize"
 	"github.com/iancoleman/orderedmap"
 	"github.com/lade-io/go-lade"
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 	"github.com/mgutz/ansi"
 	"github.com/olekukonko/ts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -33,18 +37,66 @@ var (
 	validPath    = regexp.MustCompile(`^(/[a-zA-Z0-9-_]+)+$`)
 )
 
+var (
+	activeCmd      *cobra.Command
+	answersFile    string
+	assumeYes      bool
+	nonInteractive bool
+)
+
 type optionsFunc func(*lade.Client) (*orderedmap.OrderedMap, error)
 
+func askApp(client *lade.Client, result *string) error {
+	return askAppDir(client, ".", result)
+}
+
+func askAppDir(client *lade.Client, dir string, result *string) error {
+	if *result == "" {
+		app, err := getLinkedApp(dir)
+		if err != nil {
+			return err
+		}
+		*result = app
+	}
+	getDirName := func() string {
+		path, err := filepath.Abs(dir)
+		if err != nil {
+			return ""
+		}
+		return filepath.Base(path)
+	}
+	return askSelect("App Name:", getDirName, client, getAppOptions, result)
+}
+
+func askApproval(msg string) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+	if nonInteractive {
+		return false, errors.New("Confirmation required, use --yes to approve")
+	}
+	return prompter.Confirm(msg, false)
+}
+
 func askError(err error) error {
 	if err == terminal.InterruptErr {
-		os.Exit(1)
+		return errAborted
 	}
 	return err
 }
 
 func askConfirm(msg string, choice bool, result interface{}) error {
-	prompt := &survey.Confirm{Message: msg, Default: choice}
-	return askError(survey.AskOne(prompt, result, nil))
+	if nonInteractive {
+		if isZero(result) {
+			return core.WriteAnswer(result, "", choice)
+		}
+		return nil
+	}
+	answer, err := prompter.Confirm(msg, choice)
+	if err != nil {
+		return err
+	}
+	return core.WriteAnswer(result, "", answer)
 }
 
 func askInput(msg string, value, result interface{}, validator survey.Validator) error {
@@ -54,6 +106,9 @@ func askInput(msg string, value, result interface{}, validator survey.Validator)
 		}
 		return nil
 	}
+	if nonInteractive {
+		return askRequired(msg, result)
+	}
 	var choice string
 	switch v := value.(type) {
 	case func() string:
@@ -63,14 +118,39 @@ func askInput(msg string, value, result interface{}, validator survey.Validator)
 	case int:
 		choice = strconv.Itoa(v)
 	}
-	prompt := &survey.Input{Message: msg, Default: choice}
-	return askError(survey.AskOne(prompt, result, survey.WithValidator(validator)))
+	answer, err := prompter.Input(msg, choice, validator)
+	if err != nil {
+		return err
+	}
+	return core.WriteAnswer(result, "", answer)
+}
+
+func askRequired(msg string, result interface{}) error {
+	name := strings.TrimSuffix(msg, ":")
+	if activeCmd == nil {
+		return fmt.Errorf("%s is required", name)
+	}
+	ptr := reflect.ValueOf(result).Pointer()
+	var flag *pflag.Flag
+	activeCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		v := reflect.ValueOf(f.Value)
+		if f.Usage == name || flag == nil && v.Kind() == reflect.Ptr && v.Pointer() == ptr {
+			flag = f
+		}
+	})
+	if flag != nil {
+		return fmt.Errorf("%s is required, use --%s to set it", name, flag.Name)
+	}
+	return fmt.Errorf("%s is required, use %s", name, activeCmd.UseLine())
 }
 
 func askSelect(msg string, value interface{}, client *lade.Client, fn optionsFunc, result interface{}) error {
 	if !isZero(result) {
 		return nil
 	}
+	if nonInteractive {
+		return askRequired(msg, result)
+	}
 	var wg sync.WaitGroup
 	var choice string
 	switch v := value.(type) {
@@ -90,17 +170,12 @@ func askSelect(msg string, value interface{}, client *lade.Client, fn optionsFun
 		return err
 	}
 	wg.Wait()
-	prompt := &survey.Select{Message: msg, Options: options.Keys(), PageSize: getPageSize()}
 	if choice != "" {
-		key, ok := options.GetKey(choice)
-		if ok {
-			prompt.Default = key
-		}
+		choice, _ = options.GetKey(choice)
 	}
-	var answer string
-	err = survey.AskOne(prompt, &answer, nil)
+	answer, err := prompter.Select(msg, options.Keys(), choice)
 	if err != nil {
-		return askError(err)
+		return err
 	}
 	value, ok := options.Get(answer)
 	if ok {
@@ -113,15 +188,16 @@ func askMultiSelect(msg string, client *lade.Client, fn optionsFunc, result inte
 	if !isZero(result) {
 		return nil
 	}
+	if nonInteractive {
+		return askRequired(msg, result)
+	}
 	options, err := fn(client)
 	if err != nil {
 		return err
 	}
-	var answers []string
-	prompt := &survey.MultiSelect{Message: msg, Options: options.Keys(), PageSize: getPageSize()}
-	err = survey.AskOne(prompt, &answers, survey.WithValidator(validator))
+	answers, err := prompter.MultiSelect(msg, options.Keys(), validator)
 	if err != nil {
-		return askError(err)
+		return err
 	}
 	var values []string
 	for _, answer := range answers {
@@ -269,6 +345,17 @@ func getProcessOptions(appName string) optionsFunc {
 	}
 }
 
+func getProfileOptions(client *lade.Client) (*orderedmap.OrderedMap, error) {
+	if len(conf.Profiles) == 0 {
+		return nil, errors.New("You have not created any profiles")
+	}
+	options := orderedmap.New()
+	for _, name := range conf.ProfileNames() {
+		options.Set(name, name)
+	}
+	return options, nil
+}
+
 func getRegionOptions(client *lade.Client) (*orderedmap.OrderedMap, error) {
 	regions, err := client.Region.List()
 	if err != nil {
@@ -316,6 +403,27 @@ func getVersionOptions(serviceName string) optionsFunc {
 	}
 }
 
+func initInteractive(cmd *cobra.Command) error {
+	activeCmd = cmd
+	if answersFile != "" {
+		if nonInteractive && cmd.Flags().Changed("non-interactive") {
+			return errors.New("Cannot use --answers with --non-interactive")
+		}
+		scripted, err := newScriptedPrompter(answersFile)
+		if err != nil {
+			return err
+		}
+		prompter = scripted
+		nonInteractive = false
+		return nil
+	}
+	if !cmd.Flags().Changed("non-interactive") {
+		_, ci := os.LookupEnv("CI")
+		nonInteractive = ci || !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd())
+	}
+	return nil
+}
+
 func getPageSize() int {
 	size, err := ts.GetSize()
 	if err != nil {
@@ -352,36 +460,61 @@ func printBool(val bool) string {
 	return "No"
 }
 
-func printDeployLog(cancel context.CancelFunc, entry *lade.LogEntry) {
-	if entry.Source == "stderr" {
-		if entry.Line == io.EOF.Error() {
-			cancel()
-			return
+func printJSONLog(opts *logPrintOpts) logHandler {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	return func(cancel context.CancelFunc, entry *logEntry) {
+		record := &logRecord{
+			App:       entry.App,
+			Addon:     opts.Addon,
+			Process:   strings.SplitN(entry.Name, ".", 2)[0],
+			Container: entry.Name,
+			Source:    entry.Source,
+			Line:      entry.Line,
+		}
+		if !entry.Time.IsZero() {
+			t := opts.localTime(entry.Time)
+			record.Time = &t
 		}
-		log.Fatal(entry.Line)
+		enc.Encode(record)
 	}
-	fmt.Println(entry.Line)
 }
 
-func printLog(cancel context.CancelFunc, entry *lade.LogEntry) {
-	fmt.Println(entry.Line)
+// printStatus prints a dim status line to stderr.
+func printStatus(msg string) {
+	out := colorable.NewColorableStderr()
+	if !isatty.IsTerminal(os.Stderr.Fd()) {
+		fmt.Fprintln(out, msg)
+		return
+	}
+	fmt.Fprintln(out, ansi.Color(msg, "default+d"))
+}
+
+func printLog(opts *logPrintOpts) logHandler {
+	return func(cancel context.CancelFunc, entry *logEntry) {
+		fmt.Println(opts.timestamp(entry) + entry.Line)
+	}
 }
 
-func printNameLog(width int) lade.LogHandler {
+func printNameLog(width int, opts *logPrintOpts) logHandler {
 	out := colorable.NewColorableStdout()
 	if !isatty.IsTerminal(os.Stdout.Fd()) {
 		ansi.DisableColors(true)
 	}
 	colors := []string{"yellow", "green", "cyan", "blue", "magenta", "red"}
 	names := map[string]string{}
-	return func(cancel context.CancelFunc, entry *lade.LogEntry) {
-		name, ok := names[entry.Name]
+	return func(cancel context.CancelFunc, entry *logEntry) {
+		label := entry.Name
+		if opts.ShowApp {
+			label = entry.App + "/" + entry.Name
+		}
+		name, ok := names[label]
 		if !ok {
 			color := colors[len(names)%len(colors)]
-			name = ansi.Color(fmt.Sprintf("%-*s | ", width+2, entry.Name), color)
-			names[entry.Name] = name
+			name = ansi.Color(fmt.Sprintf("%-*s | ", width+2, label), color)
+			names[label] = name
 		}
-		fmt.Fprintln(out, name+entry.Line)
+		fmt.Fprintln(out, opts.timestamp(entry)+name+entry.Line)
 	}
 }
 
@@ -392,6 +525,13 @@ func printPrice(val float64, prec int) string {
 	return strconv.FormatFloat(val, 'f', prec, 64)
 }
 
+func printTime(val *time.Time, zero string) string {
+	if val == nil || val.IsZero() {
+		return zero
+	}
+	return humanize.Time(*val)
+}
+
 func processInfo(processes []*lade.Process) string {
 	results := []string{}
 	for _, process := range processes {
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lade-io/go-lade"
+)
+
+// captureStdout returns what print writes to stdout.
+func captureStdout(t *testing.T, print func()) string {
+	t.Helper()
+	file, err := ioutil.TempFile(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+	print()
+	data, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestPrintLog(t *testing.T) {
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("CEST", 2*60*60)
+	at := time.Date(2026, 10, 1, 10, 0, 0, 500*int(time.Millisecond), time.UTC)
+	entries := []*logEntry{
+		{LogEntry: lade.LogEntry{Name: "web.1", Source: "stdout", Line: "started"}, App: "api", Time: at},
+		{LogEntry: lade.LogEntry{Name: "worker.2", Source: "stderr", Line: "failed"}, App: "jobs", Time: at},
+	}
+	tests := []struct {
+		name  string
+		print func(opts *logPrintOpts) logHandler
+		opts  *logPrintOpts
+		want  string
+	}{
+		{
+			name:  "plain",
+			print: printLog,
+			opts:  &logPrintOpts{},
+			want:  "started\nfailed\n",
+		},
+		{
+			name:  "local timestamps",
+			print: printLog,
+			opts:  &logPrintOpts{Timestamps: true},
+			want:  "2026-10-01T12:00:00.500+02:00 started\n2026-10-01T12:00:00.500+02:00 failed\n",
+		},
+		{
+			name:  "utc timestamps",
+			print: printLog,
+			opts:  &logPrintOpts{UTC: true},
+			want:  "2026-10-01T10:00:00.500Z started\n2026-10-01T10:00:00.500Z failed\n",
+		},
+		{
+			name: "names",
+			print: func(opts *logPrintOpts) logHandler {
+				return printNameLog(8, opts)
+			},
+			opts: &logPrintOpts{},
+			want: "web.1      | started\nworker.2   | failed\n",
+		},
+		{
+			name: "apps and timestamps",
+			print: func(opts *logPrintOpts) logHandler {
+				return printNameLog(13, opts)
+			},
+			opts: &logPrintOpts{ShowApp: true, UTC: true},
+			want: "2026-10-01T10:00:00.500Z api/web.1       | started\n" +
+				"2026-10-01T10:00:00.500Z jobs/worker.2   | failed\n",
+		},
+		{
+			name:  "jsonl",
+			print: printJSONLog,
+			opts:  &logPrintOpts{UTC: true},
+			want: `{"app":"api","process":"web","container":"web.1","source":"stdout","time":"2026-10-01T10:00:00.5Z","line":"started"}` + "\n" +
+				`{"app":"jobs","process":"worker","container":"worker.2","source":"stderr","time":"2026-10-01T10:00:00.5Z","line":"failed"}` + "\n",
+		},
+		{
+			name:  "jsonl local",
+			print: printJSONLog,
+			opts:  &logPrintOpts{Addon: "mydb"},
+			want: `{"app":"api","addon":"mydb","process":"web","container":"web.1","source":"stdout","time":"2026-10-01T12:00:00.5+02:00","line":"started"}` + "\n" +
+				`{"app":"jobs","addon":"mydb","process":"worker","container":"worker.2","source":"stderr","time":"2026-10-01T12:00:00.5+02:00","line":"failed"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := captureStdout(t, func() {
+				handler := tt.print(tt.opts)
+				for _, entry := range entries {
+					handler(nil, entry)
+				}
+			})
+			if got != tt.want {
+				t.Errorf("got output\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintJSONLogNoTime(t *testing.T) {
+	got := captureStdout(t, func() {
+		printJSONLog(&logPrintOpts{})(nil, &logEntry{LogEntry: lade.LogEntry{Name: "web.1", Line: "a <b> & c"}})
+	})
+	if want := `{"process":"web","container":"web.1","source":"","line":"a <b> & c"}`; strings.TrimSpace(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var prompter Prompter = surveyPrompter{}
+
+type Prompter interface {
+	Select(msg string, options []string, choice string) (string, error)
+	MultiSelect(msg string, options []string, validator survey.Validator) ([]string, error)
+	Input(msg, choice string, validator survey.Validator) (string, error)
+	Confirm(msg string, choice bool) (bool, error)
+	Editor(msg, text string) (string, error)
+	Password(msg string, validator survey.Validator) (string, error)
+	// Interactive reports whether a person answers the prompts, so that a
+	// rejected answer can be asked for again.
+	Interactive() bool
+}
+
+type surveyPrompter struct{}
+
+func (surveyPrompter) Select(msg string, options []string, choice string) (string, error) {
+	prompt := &survey.Select{Message: msg, Options: options, PageSize: getPageSize()}
+	if choice != "" {
+		prompt.Default = choice
+	}
+	var answer string
+	err := survey.AskOne(prompt, &answer, nil)
+	return answer, askError(err)
+}
+
+func (surveyPrompter) MultiSelect(msg string, options []string, validator survey.Validator) ([]string, error) {
+	prompt := &survey.MultiSelect{Message: msg, Options: options, PageSize: getPageSize()}
+	var answers []string
+	err := survey.AskOne(prompt, &answers, validatorOpts(validator)...)
+	return answers, askError(err)
+}
+
+func (surveyPrompter) Input(msg, choice string, validator survey.Validator) (string, error) {
+	prompt := &survey.Input{Message: msg, Default: choice}
+	var answer string
+	err := survey.AskOne(prompt, &answer, validatorOpts(validator)...)
+	return answer, askError(err)
+}
+
+func (surveyPrompter) Confirm(msg string, choice bool) (bool, error) {
+	prompt := &survey.Confirm{Message: msg, Default: choice}
+	var answer bool
+	err := survey.AskOne(prompt, &answer, nil)
+	return answer, askError(err)
+}
+
+func (surveyPrompter) Editor(msg, text string) (string, error) {
+	prompt := &survey.Editor{Message: msg, Default: text, HideDefault: true, AppendDefault: true}
+	var answer string
+	err := survey.AskOne(prompt, &answer, nil)
+	return answer, askError(err)
+}
+
+func (surveyPrompter) Password(msg string, validator survey.Validator) (string, error) {
+	prompt := &survey.Password{Message: msg}
+	var answer string
+	err := survey.AskOne(prompt, &answer, validatorOpts(validator)...)
+	return answer, askError(err)
+}
+
+func (surveyPrompter) Interactive() bool {
+	return true
+}
+
+type scriptedPrompter struct {
+	answers map[string]interface{}
+}
+
+func newScriptedPrompter(answersFile string) (*scriptedPrompter, error) {
+	data, err := ioutil.ReadFile(answersFile)
+	if err != nil {
+		return nil, err
+	}
+	answers := map[string]interface{}{}
+	if err = yaml.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("Answers error: %s", err)
+	}
+	p := &scriptedPrompter{answers: map[string]interface{}{}}
+	for key, answer := range answers {
+		p.answers[strings.TrimSuffix(key, ":")] = answer
+	}
+	return p, nil
+}
+
+func (p *scriptedPrompter) answer(msg string) (interface{}, error) {
+	name := strings.TrimSuffix(msg, ":")
+	answer, ok := p.answers[name]
+	if !ok {
+		return nil, fmt.Errorf("No answer given for %s", name)
+	}
+	return answer, nil
+}
+
+// text returns the answer for msg, or choice when no answer is given.
+func (p *scriptedPrompter) text(msg, choice string, validator survey.Validator) (string, error) {
+	answer, err := p.answer(msg)
+	if err != nil && choice == "" {
+		return "", err
+	}
+	text := choice
+	if err == nil {
+		text = fmt.Sprint(answer)
+	}
+	if validator != nil {
+		if err = validator(text); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+func (p *scriptedPrompter) Select(msg string, options []string, choice string) (string, error) {
+	answer, err := p.text(msg, choice, nil)
+	if err != nil {
+		return "", err
+	}
+	if !contains(options, answer) {
+		return "", optionsError(msg, options)
+	}
+	return answer, nil
+}
+
+func (p *scriptedPrompter) MultiSelect(msg string, options []string, validator survey.Validator) ([]string, error) {
+	answer, err := p.answer(msg)
+	if err != nil {
+		return nil, err
+	}
+	answers := []string{}
+	switch v := answer.(type) {
+	case []interface{}:
+		for _, item := range v {
+			answers = append(answers, fmt.Sprint(item))
+		}
+	default:
+		answers = append(answers, fmt.Sprint(v))
+	}
+	for _, answer := range answers {
+		if !contains(options, answer) {
+			return nil, optionsError(msg, options)
+		}
+	}
+	if validator != nil {
+		if err = validator(answers); err != nil {
+			return nil, err
+		}
+	}
+	return answers, nil
+}
+
+func (p *scriptedPrompter) Input(msg, choice string, validator survey.Validator) (string, error) {
+	return p.text(msg, choice, validator)
+}
+
+func (p *scriptedPrompter) Confirm(msg string, choice bool) (bool, error) {
+	answer, err := p.answer(msg)
+	if err != nil {
+		return false, err
+	}
+	switch v := answer.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		if confirm, err := strconv.ParseBool(v); err == nil {
+			return confirm, nil
+		}
+	}
+	return false, fmt.Errorf("Answer for %s must be yes or no", strings.TrimSuffix(msg, ":"))
+}
+
+func (p *scriptedPrompter) Editor(msg, text string) (string, error) {
+	return p.text(msg, text, nil)
+}
+
+func (p *scriptedPrompter) Password(msg string, validator survey.Validator) (string, error) {
+	return p.text(msg, "", validator)
+}
+
+func (p *scriptedPrompter) Interactive() bool {
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func optionsError(msg string, options []string) error {
+	name := strings.TrimSuffix(msg, ":")
+	return fmt.Errorf("Answer for %s must be one of %s", name, strings.Join(options, ", "))
+}
+
+func validatorOpts(validator survey.Validator) []survey.AskOpt {
+	if validator == nil {
+		return nil
+	}
+	return []survey.AskOpt{survey.WithValidator(validator)}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScriptedPrompter(t *testing.T) {
+	p := &scriptedPrompter{answers: map[string]interface{}{
+		"App Name": "myapp",
+		"Plan":     "huge",
+		"Remove":   "yes",
+		"Keep":     false,
+		"Maybe":    "perhaps",
+		"Apps":     []interface{}{"api", "web"},
+		"Blank":    "",
+	}}
+	options := []string{"basic", "large"}
+	required := func(val interface{}) error {
+		if val.(string) == "" {
+			return errors.New("Value is required")
+		}
+		return nil
+	}
+	tests := []struct {
+		name string
+		ask  func() (interface{}, error)
+		want interface{}
+		err  string
+	}{
+		{"input answer", func() (interface{}, error) { return p.Input("App Name:", "dir", nil) }, "myapp", ""},
+		{"input default", func() (interface{}, error) { return p.Input("Command:", "npm start", nil) }, "npm start", ""},
+		{"input missing", func() (interface{}, error) { return p.Input("Command:", "", nil) }, "", "No answer given for Command"},
+		{"input validator", func() (interface{}, error) { return p.Input("Blank:", "", required) }, "", "Value is required"},
+		{"select default", func() (interface{}, error) { return p.Select("Region:", options, "basic") }, "basic", ""},
+		{"select invalid", func() (interface{}, error) { return p.Select("Plan:", options, "basic") }, "", "Answer for Plan must be one of basic, large"},
+		{"select missing", func() (interface{}, error) { return p.Select("Region:", options, "") }, "", "No answer given for Region"},
+		{"editor default", func() (interface{}, error) { return p.Editor("Env:", "A=1") }, "A=1", ""},
+		{"confirm yes", func() (interface{}, error) { return p.Confirm("Remove", false) }, true, ""},
+		{"confirm bool", func() (interface{}, error) { return p.Confirm("Keep", true) }, false, ""},
+		{"confirm invalid", func() (interface{}, error) { return p.Confirm("Maybe", false) }, false, "Answer for Maybe must be yes or no"},
+		{"confirm missing", func() (interface{}, error) { return p.Confirm("Sure?", true) }, false, "No answer given for Sure?"},
+		{"multi select", func() (interface{}, error) { return p.MultiSelect("Apps:", []string{"api", "web"}, nil) }, []string{"api", "web"}, ""},
+		{"password missing", func() (interface{}, error) { return p.Password("Password:", nil) }, "", "No answer given for Password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ask()
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewScriptedPrompter(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		answers string
+		err     string
+	}{
+		{name: "keys with colons", answers: "App Name:: myapp\nPlan: basic\nApps:: [api, web]\n"},
+		{name: "broken yaml", answers: "Plan: [basic\n", err: "Answers error: yaml: line 1: did not find expected ',' or ']'"},
+		{name: "not a map", answers: "- basic\n", err: "Answers error: yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into map[string]interface {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answersFile := filepath.Join(dir, "answers.yml")
+			if err := ioutil.WriteFile(answersFile, []byte(tt.answers), 0600); err != nil {
+				t.Fatal(err)
+			}
+			p, err := newScriptedPrompter(answersFile)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if answer, err := p.Input("App Name:", "", nil); err != nil || answer != "myapp" {
+				t.Errorf("got app name %q and error %v, want myapp", answer, err)
+			}
+			if answer, err := p.Select("Plan:", []string{"basic"}, ""); err != nil || answer != "basic" {
+				t.Errorf("got plan %q and error %v, want basic", answer, err)
+			}
+			if _, err := p.MultiSelect("Apps:", []string{"api"}, nil); err == nil || err.Error() != "Answer for Apps must be one of api" {
+				t.Errorf("got error %v for an unknown option", err)
+			}
+		})
+	}
+	if _, err := newScriptedPrompter(filepath.Join(dir, "missing.yml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got error %v for a missing answers file", err)
+	}
+}
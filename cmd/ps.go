@@ -5,10 +5,18 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/lade-io/go-lade"
-	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 )
 
+var taskColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return taskName(v.(*lade.Container)) }},
+	{"ID", false, func(v interface{}) interface{} { return v.(*lade.Container).ID }},
+	{"TYPE", false, func(v interface{}) interface{} { return v.(*lade.Container).Process.Type }},
+	{"PLAN", true, func(v interface{}) interface{} { return v.(*lade.Container).PlanID }},
+	{"STARTED", true, func(v interface{}) interface{} { return humanize.Time(v.(*lade.Container).CreatedAt) }},
+	{"COMMAND", true, func(v interface{}) interface{} { return v.(*lade.Container).Process.Command }},
+}
+
 var psCmd = func() *cobra.Command {
 	var appName string
 	cmd := &cobra.Command{
@@ -24,29 +32,31 @@ var psCmd = func() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	addColumnsFlag(cmd, taskColumns)
 	return cmd
 }()
 
 func psRun(client *lade.Client, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	containers, err := client.Container.List(appName)
 	if err != nil {
 		return err
 	}
-	t := table.New("NAME", "PLAN", "STARTED", "COMMAND")
+	tasks := []*lade.Container{}
 	for _, container := range containers {
-		if container.Process == nil {
-			continue
-		}
-		number := container.Process.Number
-		if number == 0 {
-			number = container.Number
+		if container.Process != nil {
+			tasks = append(tasks, container)
 		}
-		name := fmt.Sprintf("%s.%d", container.Process.Type, number)
-		t.AddRow(name, container.PlanID, humanize.Time(container.CreatedAt), container.Process.Command)
 	}
-	t.Print()
-	return nil
+	return printList(tasks, taskColumns)
+}
+
+func taskName(container *lade.Container) string {
+	number := container.Process.Number
+	if number == 0 {
+		number = container.Number
+	}
+	return fmt.Sprintf("%s.%d", container.Process.Type, number)
 }
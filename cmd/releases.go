@@ -0,0 +1,416 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/iancoleman/orderedmap"
+	"github.com/lade-io/go-lade"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const releasePollInterval = 3 * time.Second
+
+const (
+	releaseCanceled  = "canceled"
+	releaseFailed    = "failed"
+	releaseSucceeded = "succeeded"
+)
+
+type release struct {
+	*lade.Release
+	Status   string     `json:"status"`
+	Owner    *lade.User `json:"owner"`
+	Checksum string     `json:"checksum"`
+	Message  string     `json:"message"`
+	Image    string     `json:"image"`
+}
+
+type releaseCreateOpts struct {
+	Source         *sourceArchive
+	BuildArgs      map[string]string
+	BuildSecrets   map[string]string
+	Dockerfile     string
+	Target         string
+	Image          string
+	RegistryAuth   *registryAuth
+	RegistrySecret string
+}
+
+type releaseInfo struct {
+	*release
+	Log []string `json:"log"`
+}
+
+var releaseColumns = []column{
+	{"VERSION", true, func(v interface{}) interface{} { return "v" + strconv.Itoa(v.(*release).Version) }},
+	{"ID", false, func(v interface{}) interface{} { return v.(*release).ID }},
+	{"CREATED", true, func(v interface{}) interface{} { return humanize.Time(v.(*release).CreatedAt) }},
+	{"STATUS", true, func(v interface{}) interface{} { return v.(*release).Status }},
+	{"ACTIVE", true, func(v interface{}) interface{} { return printBool(v.(*release).Active) }},
+	{"DEPLOYED BY", true, func(v interface{}) interface{} { return printOwner(v.(*release).Owner) }},
+	{"CHECKSUM", true, func(v interface{}) interface{} { return printChecksum(v.(*release).Checksum) }},
+	{"BRANCH", false, func(v interface{}) interface{} { return v.(*release).Branch }},
+	{"COMMIT", false, func(v interface{}) interface{} { return v.(*release).Commit }},
+}
+
+var releasesCmd = &cobra.Command{
+	Use:   "releases",
+	Short: "Manage app releases",
+}
+
+var releasesListCmd = func() *cobra.Command {
+	var appName string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List releases of an app",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return releasesListRun(client, api, appName)
+		},
+	}
+	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	addColumnsFlag(cmd, releaseColumns)
+	return cmd
+}()
+
+var releasesRollbackCmd = func() *cobra.Command {
+	var appName string
+	cmd := &cobra.Command{
+		Use:   "rollback <version>",
+		Short: "Roll back an app to a previous release",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			var version string
+			if len(args) > 0 {
+				version = args[0]
+			}
+			return releasesRollbackRun(client, api, appName, version)
+		},
+	}
+	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	return cmd
+}()
+
+var releasesShowCmd = func() *cobra.Command {
+	var appName string
+	printOpts := &logPrintOpts{}
+	cmd := &cobra.Command{
+		Use:   "show <version>",
+		Short: "Show release info",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			var version string
+			if len(args) > 0 {
+				version = args[0]
+			}
+			return releasesShowRun(client, api, printOpts, appName, version)
+		},
+	}
+	cmd.Flags().StringVarP(&appName, "app", "a", "", "App Name")
+	addLogPrintFlags(cmd, printOpts)
+	return cmd
+}()
+
+var releasesWaitCmd = func() *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "wait <release-id>",
+		Short: "Wait for a release to finish",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return releasesWaitRun(api, args[0], timeout)
+		},
+	}
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", 15*time.Minute, "Wait Timeout")
+	return cmd
+}()
+
+func init() {
+	releasesCmd.AddCommand(releasesListCmd)
+	releasesCmd.AddCommand(releasesRollbackCmd)
+	releasesCmd.AddCommand(releasesShowCmd)
+	releasesCmd.AddCommand(releasesWaitCmd)
+}
+
+func (a *apiClient) createRelease(appID string, opts *releaseCreateOpts) (rel *release, err error) {
+	fields := map[string]string{
+		"dockerfile":      opts.Dockerfile,
+		"target":          opts.Target,
+		"image":           opts.Image,
+		"registry_secret": opts.RegistrySecret,
+	}
+	if len(opts.BuildArgs) > 0 {
+		data, err := json.Marshal(opts.BuildArgs)
+		if err != nil {
+			return nil, err
+		}
+		fields["build_args"] = string(data)
+	}
+	if len(opts.BuildSecrets) > 0 {
+		data, err := json.Marshal(opts.BuildSecrets)
+		if err != nil {
+			return nil, err
+		}
+		fields["build_secrets"] = string(data)
+	}
+	if opts.RegistryAuth != nil {
+		data, err := json.Marshal(opts.RegistryAuth)
+		if err != nil {
+			return nil, err
+		}
+		fields["registry_auth"] = string(data)
+	}
+	var file *lade.File
+	if opts.Source != nil {
+		fields["commit"] = opts.Source.Commit
+		fields["message"] = opts.Source.Message
+		file = opts.Source.File()
+	}
+	rel = new(release)
+	err = a.doForm("apps/"+appID+"/releases", fields, "source", file, rel)
+	return
+}
+
+func (a *apiClient) getRelease(appID, version string) (rel *release, err error) {
+	rel = new(release)
+	err = a.doGet("apps/"+appID+"/releases/"+version, rel)
+	return
+}
+
+func (a *apiClient) getReleaseByID(id string) (rel *release, err error) {
+	rel = new(release)
+	err = a.doGet("releases/"+id, rel)
+	return
+}
+
+func (a *apiClient) listReleases(appID string) (releases []*release, err error) {
+	err = a.doGet("apps/"+appID+"/releases", &releases)
+	return
+}
+
+func (a *apiClient) rollbackRelease(appID, version string) (rel *release, err error) {
+	rel = new(release)
+	err = a.doCreate("apps/"+appID+"/releases/"+version+"/rollback", nil, rel)
+	return
+}
+
+func releasesListRun(client *lade.Client, api *apiClient, appName string) error {
+	if err := askApp(client, &appName); err != nil {
+		return err
+	}
+	releases, err := api.listReleases(appName)
+	if err != nil {
+		return err
+	}
+	return printList(releases, releaseColumns)
+}
+
+func releasesRollbackRun(client *lade.Client, api *apiClient, appName, version string) error {
+	if err := askApp(client, &appName); err != nil {
+		return err
+	}
+	if err := askSelect("Version:", "", client, getReleaseOptions(api, appName), &version); err != nil {
+		return err
+	}
+	rel, err := api.getRelease(appName, strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return err
+	}
+	if rel.Active {
+		return fmt.Errorf("Release v%d is already active", rel.Version)
+	}
+	if rel.Status == releaseFailed {
+		return fmt.Errorf("Release v%d failed and cannot be activated", rel.Version)
+	}
+	confirm, err := askApproval(fmt.Sprintf("Do you really want to roll back %s to v%d?", appName, rel.Version))
+	if err != nil || !confirm {
+		return err
+	}
+	rel, err = api.rollbackRelease(appName, strconv.Itoa(rel.Version))
+	if err != nil {
+		return err
+	}
+	if err = followRelease(api, rel); err != nil {
+		return err
+	}
+	fmt.Printf("Rollback finished use \"%s logs -a %s -f\" to view app logs\n", RootCmd.Use, appName)
+	return nil
+}
+
+func releasesShowRun(client *lade.Client, api *apiClient, printOpts *logPrintOpts, appName, version string) error {
+	if err := askApp(client, &appName); err != nil {
+		return err
+	}
+	if err := askSelect("Version:", "", client, getReleaseOptions(api, appName), &version); err != nil {
+		return err
+	}
+	rel, err := api.getRelease(appName, strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return err
+	}
+	info := &releaseInfo{release: rel, Log: []string{}}
+	opts := &logStreamOpts{}
+	if printOpts.Timestamps || printOpts.UTC {
+		opts.needTime = "--timestamps"
+	}
+	err = api.streamReleaseLogs(getContext(), rel, opts, func(cancel context.CancelFunc, entry *logEntry) {
+		info.Log = append(info.Log, printOpts.timestamp(entry)+entry.Line)
+	})
+	if err != nil {
+		return err
+	}
+	return getPrinter().Print(info, func() {
+		t := table.New("Version:", "v"+strconv.Itoa(rel.Version))
+		t.AddRow("Created:", humanize.Time(rel.CreatedAt))
+		t.AddRow("Status:", rel.Status)
+		t.AddRow("Active:", printBool(rel.Active))
+		t.AddRow("Deployed By:", printOwner(rel.Owner))
+		t.AddRow("Checksum:", rel.Checksum)
+		if rel.Commit != "" {
+			t.AddRow("Commit:", rel.Commit)
+		}
+		if rel.Image != "" {
+			t.AddRow("Image:", rel.Image)
+		}
+		if rel.Message != "" {
+			t.AddRow("Message:", strings.SplitN(rel.Message, "\n", 2)[0])
+		}
+		t.Print()
+		if len(info.Log) > 0 {
+			fmt.Println()
+			for _, line := range info.Log {
+				fmt.Println(line)
+			}
+		}
+	})
+}
+
+func releasesWaitRun(api *apiClient, id string, timeout time.Duration) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("Invalid release ID %s", id)
+	}
+	rel, err := waitRelease(api, id, timeout)
+	if err != nil {
+		return err
+	}
+	err = getPrinter().Print(rel, func() {
+		fmt.Printf("Release v%d %s\n", rel.Version, rel.Status)
+	})
+	if err == nil && rel.Status != releaseSucceeded {
+		deployErr := &deployError{ReleaseID: rel.ID, Version: rel.Version, Stage: stageRelease, Status: rel.Status}
+		if rel.Status != releaseFailed && rel.Status != releaseCanceled {
+			deployErr.Message = rel.Status
+		}
+		err = deployErr
+	}
+	return err
+}
+
+// waitRelease polls the release until it has finished or timeout passes.
+func waitRelease(api *apiClient, id string, timeout time.Duration) (rel *release, err error) {
+	done, err := poll(timeout, func() (bool, error) {
+		rel, err = api.getReleaseByID(id)
+		return err == nil && rel.isFinished(), err
+	})
+	if err == nil && !done {
+		err = fmt.Errorf("Timed out waiting for release v%d after %s", rel.Version, timeout)
+	}
+	return
+}
+
+// poll calls check every releasePollInterval until it reports done or
+// timeout passes. A zero timeout polls forever.
+func poll(timeout time.Duration, check func() (bool, error)) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := check()
+		if done || err != nil {
+			return done, err
+		}
+		wait := releasePollInterval
+		if timeout > 0 {
+			left := time.Until(deadline)
+			if left <= 0 {
+				return false, nil
+			}
+			if left < wait {
+				wait = left
+			}
+		}
+		select {
+		case <-getContext().Done():
+			return false, getContext().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (r *release) isFinished() bool {
+	switch r.Status {
+	case releaseCanceled, releaseFailed, releaseSucceeded:
+		return true
+	}
+	return false
+}
+
+func getReleaseOptions(api *apiClient, appName string) optionsFunc {
+	return func(client *lade.Client) (*orderedmap.OrderedMap, error) {
+		releases, err := api.listReleases(appName)
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			return nil, errors.New("There are no releases available")
+		}
+		options := orderedmap.New()
+		for _, rel := range releases {
+			version := strconv.Itoa(rel.Version)
+			key := fmt.Sprintf("v%s (%s, %s)", version, rel.Status, humanize.Time(rel.CreatedAt))
+			options.Set(key, version)
+		}
+		return options, nil
+	}
+}
+
+func printChecksum(checksum string) string {
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return checksum
+}
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/lade-io/lade/config"
 	"github.com/rodaine/table"
@@ -16,12 +20,44 @@ var (
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: true,
 		},
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
+			if err := initInteractive(cmd); err != nil {
+				return err
+			}
+			return validateOutput()
 		},
 	}
 )
 
+// Execute runs RootCmd with a context that is canceled on interrupt. A second
+// interrupt stops the process at once.
+func Execute() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	RootCmd.SilenceErrors = true
+	err := RootCmd.ExecuteContext(ctx)
+	if ctx.Err() != nil {
+		err = errAborted
+	}
+	if err != nil {
+		RootCmd.PrintErrln("Error:", err.Error())
+	}
+	return err
+}
+
+// getContext returns the context of the running command.
+func getContext() context.Context {
+	if ctx := RootCmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
 func SetVersion(version string) {
 	RootCmd.Version = version
 }
@@ -32,23 +68,39 @@ func init() {
 	RootCmd.SetUsageTemplate(usageTemplate)
 
 	RootCmd.PersistentFlags().BoolP("help", "h", false, "Print help message")
+	RootCmd.PersistentFlags().StringVar(&answersFile, "answers", "", "Answer prompts from a YAML file")
+	RootCmd.PersistentFlags().StringVar(&outputTemplate, "format", "", "Output Go template")
+	RootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Fail instead of prompting for input")
+	RootCmd.PersistentFlags().StringVar(&conf.ProfileName, "profile", "", "Auth profile")
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (json, jsonl, table, yaml)")
+	RootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Approve all confirmations")
 	RootCmd.Flags().BoolP("version", "v", false, "Print version and exit")
 
 	RootCmd.AddCommand(addonsCmd)
 	RootCmd.AddCommand(appsCmd)
+	RootCmd.AddCommand(authCmd)
 	RootCmd.AddCommand(deployCmd)
 	RootCmd.AddCommand(disksCmd)
 	RootCmd.AddCommand(domainsCmd)
 	RootCmd.AddCommand(envCmd)
+	RootCmd.AddCommand(imagesCmd)
+	RootCmd.AddCommand(linkCmd)
 	RootCmd.AddCommand(loginCmd)
 	RootCmd.AddCommand(logoutCmd)
 	RootCmd.AddCommand(logsCmd)
+	RootCmd.AddCommand(pipelinesCmd)
 	RootCmd.AddCommand(plansCmd)
+	RootCmd.AddCommand(profilesCmd)
+	RootCmd.AddCommand(promoteCmd)
 	RootCmd.AddCommand(psCmd)
 	RootCmd.AddCommand(regionsCmd)
+	RootCmd.AddCommand(releasesCmd)
 	RootCmd.AddCommand(runCmd)
 	RootCmd.AddCommand(scaleCmd)
+	RootCmd.AddCommand(tokensCmd)
+	RootCmd.AddCommand(unlinkCmd)
 	RootCmd.AddCommand(versionCmd)
+	RootCmd.AddCommand(whoamiCmd)
 	disableFlagsUsage(RootCmd)
 }
 
@@ -37,7 +37,7 @@ var runCmd = func() *cobra.Command {
 }()
 
 func runRun(client *lade.Client, opts *lade.ProcessCreateOpts, appName string) error {
-	if err := askSelect("App Name:", getAppName, client, getAppOptions, &appName); err != nil {
+	if err := askApp(client, &appName); err != nil {
 		return err
 	}
 	if err := askInput("Command:", "", &opts.Command, survey.Required); err != nil {
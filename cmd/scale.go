@@ -34,7 +34,7 @@ var scaleCmd = func() *cobra.Command {
 }()
 
 func scaleRun(client *lade.Client, appName string, maxQuota int, opts *lade.ProcessUpdateOpts) error {
-	err := askSelect("App Name:", getAppName, client, getAppOptions, &appName)
+	err := askApp(client, &appName)
 	if err != nil {
 		return err
 	}
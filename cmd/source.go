@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/storage/pkg/archive"
+	"github.com/lade-io/go-lade"
+	"github.com/zealic/xignore"
+)
+
+// ignoreFiles are checked in order and the first one found is used.
+// .dockerignore comes before .gitignore as it was the only one honoured
+// before .ladeignore was added.
+var ignoreFiles = []string{".ladeignore", ".dockerignore", ".gitignore"}
+
+var vcsDirs = []string{".git", ".bzr", ".hg", ".svn"}
+
+type sourceArchive struct {
+	Dir        string   `json:"dir"`
+	Ignorefile string   `json:"ignorefile"`
+	Files      []string `json:"files"`
+	Size       int64    `json:"size"`
+	Commit     string   `json:"commit,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	file       *os.File
+}
+
+func packSource(dir string, includes []string) (*sourceArchive, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := getSourceRoots(dir, includes)
+	if err != nil {
+		return nil, err
+	}
+	src := &sourceArchive{Dir: dir}
+	base := dir
+	for _, root := range roots {
+		base = commonDir(base, root.dir)
+	}
+	opts := &archive.TarOptions{
+		Compression: archive.Zstd,
+		RebaseNames: map[string]string{},
+	}
+	for _, root := range roots {
+		ignorefile, files, err := matchFiles(root.dir)
+		if err != nil {
+			return nil, err
+		}
+		if root.dir == dir {
+			src.Ignorefile = ignorefile
+		}
+		rel, err := filepath.Rel(base, root.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			include := filepath.Join(rel, file)
+			opts.IncludeFiles = append(opts.IncludeFiles, include)
+			opts.RebaseNames[include] = filepath.Join(root.prefix, file)
+			src.Files = append(src.Files, filepath.ToSlash(filepath.Join(root.prefix, file)))
+		}
+	}
+	if len(src.Files) == 0 {
+		return nil, errors.New("There are no files to deploy")
+	}
+	body, err := archive.TarWithOptions(base, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	err = src.write(func(w io.Writer) error {
+		_, err := io.Copy(w, body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+func packRef(dir, ref string, includes []string) (*sourceArchive, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := getSourceRoots(dir, includes)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := getGitCommit(dir, ref)
+	if err != nil {
+		return nil, err
+	}
+	src := &sourceArchive{Dir: dir, Commit: commit.SHA, Message: commit.Message}
+	keep := make([]map[string]bool, len(roots))
+	for i, root := range roots {
+		ignorefile, files, err := matchGitFiles(root.dir, commit.SHA)
+		if err != nil {
+			return nil, err
+		}
+		if root.dir == dir {
+			src.Ignorefile = ignorefile
+		}
+		keep[i] = map[string]bool{}
+		for _, file := range files {
+			keep[i][file] = true
+		}
+	}
+	err = src.write(func(w io.Writer) error {
+		zw, err := archive.CompressStream(w, archive.Zstd)
+		if err != nil {
+			return err
+		}
+		tw := tar.NewWriter(zw)
+		for i, root := range roots {
+			files, err := writeGitArchive(tw, root.dir, commit.SHA, root.prefix, keep[i])
+			if err != nil {
+				zw.Close()
+				return err
+			}
+			src.Files = append(src.Files, files...)
+		}
+		if err = tw.Close(); err != nil {
+			zw.Close()
+			return err
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(src.Files) == 0 {
+		src.Close()
+		return nil, errors.New("There are no files to deploy")
+	}
+	return src, nil
+}
+
+// sourceRoot is a directory packed into the archive under prefix.
+type sourceRoot struct {
+	dir    string
+	prefix string
+}
+
+func getSourceRoots(dir string, includes []string) ([]*sourceRoot, error) {
+	roots := []*sourceRoot{{dir: dir}}
+	for _, include := range includes {
+		path, err := filepath.Abs(include)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("Include path %s is not a directory", include)
+		}
+		prefix := filepath.Base(path)
+		if _, err = os.Lstat(filepath.Join(dir, prefix)); err == nil {
+			return nil, fmt.Errorf("Include path %s conflicts with %s in %s", include, prefix, dir)
+		}
+		for _, root := range roots[1:] {
+			if root.prefix == prefix {
+				return nil, fmt.Errorf("Include path %s conflicts with %s", include, root.dir)
+			}
+		}
+		roots = append(roots, &sourceRoot{dir: path, prefix: prefix})
+	}
+	return roots, nil
+}
+
+func commonDir(a, b string) string {
+	for {
+		rel, err := filepath.Rel(a, b)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return a
+		}
+		if a == filepath.Dir(a) {
+			return a
+		}
+		a = filepath.Dir(a)
+	}
+}
+
+func matchFiles(dir string) (string, []string, error) {
+	var ignorefile string
+	for _, name := range ignoreFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			ignorefile = name
+			break
+		}
+	}
+	result, err := xignore.DirMatches(dir, ignoreOptions(ignorefile))
+	if err != nil {
+		return "", nil, err
+	}
+	return ignorefile, result.UnmatchedFiles, nil
+}
+
+func ignoreOptions(ignorefile string) *xignore.MatchesOptions {
+	opts := &xignore.MatchesOptions{
+		Ignorefile:     ignorefile,
+		BeforePatterns: vcsDirs,
+	}
+	if ignorefile == ".dockerignore" {
+		opts.AfterPatterns = []string{"!Dockerfile"}
+	}
+	if ignorefile == "" {
+		opts.Ignorefile = ignoreFiles[0]
+	}
+	return opts
+}
+
+func (s *sourceArchive) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
+
+func (s *sourceArchive) File() *lade.File {
+	compression := archive.Zstd
+	return &lade.File{
+		Body: s.file,
+		Name: filepath.Base(s.Dir) + "." + compression.Extension(),
+	}
+}
+
+func (s *sourceArchive) write(pack func(w io.Writer) error) (err error) {
+	if s.file, err = ioutil.TempFile("", "lade-source-"); err != nil {
+		return err
+	}
+	counter := &countWriter{Writer: s.file}
+	if err = pack(counter); err == nil {
+		s.Size = counter.n
+		_, err = s.file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		s.Close()
+	}
+	return err
+}
+
+type countWriter struct {
+	io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.Writer.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dyninc/qstring"
+	"github.com/lade-io/go-lade"
+	"github.com/r3labs/sse/v2"
+	"golang.org/x/oauth2"
+	"gopkg.in/cenkalti/backoff.v1"
+)
+
+// logStreamOpts extends lade.LogStreamOpts with an upper time bound.
+type logStreamOpts struct {
+	Follow bool      `qstring:"follow,omitempty"`
+	Since  time.Time `qstring:"since,omitempty"`
+	Until  time.Time `qstring:"until,omitempty"`
+	Tail   int       `qstring:"tail,omitempty"`
+	// needTime names the option that requires entries to have a time.
+	needTime string
+}
+
+// logEntry is a lade.LogEntry with the time the line was written.
+type logEntry struct {
+	lade.LogEntry
+	App  string    `json:"-"`
+	Time time.Time `json:"time"`
+}
+
+// logMerger reorders entries from several streams by time, holding each entry
+// for the reorder window so that late arrivals from other streams can overtake.
+type logMerger struct {
+	mu      sync.Mutex
+	entries []*mergedEntry
+	handler logHandler
+	window  time.Duration
+	done    chan struct{}
+}
+
+type mergedEntry struct {
+	*logEntry
+	cancel  context.CancelFunc
+	arrived time.Time
+}
+
+const logReorderWindow = 500 * time.Millisecond
+
+type logHandler func(cancel context.CancelFunc, entry *logEntry)
+
+// logStreamRetries caps the reconnects in a row that bring no new entries.
+const logStreamRetries = 10
+
+// newStreamBackOff returns the delays between log stream reconnects.
+var newStreamBackOff = func() backoff.BackOff {
+	retry := backoff.NewExponentialBackOff()
+	retry.MaxElapsedTime = 0
+	return backoff.WithMaxTries(retry, logStreamRetries)
+}
+
+func (a *apiClient) streamAddonLogs(ctx context.Context, addonID string, opts *logStreamOpts, handler logHandler) error {
+	return a.doStream(ctx, "addons/"+addonID+"/logs", opts, handler)
+}
+
+// streamReleaseLogs streams the build log of rel, which the server ends with
+// an EOF line on stderr.
+func (a *apiClient) streamReleaseLogs(ctx context.Context, rel *release, opts *logStreamOpts, handler logHandler) error {
+	path := fmt.Sprintf("apps/%d/releases/%d/logs", rel.AppID, rel.Version)
+	return a.doStream(ctx, path, opts, func(cancel context.CancelFunc, entry *logEntry) {
+		if entry.Source == "stderr" && entry.Line == io.EOF.Error() {
+			cancel()
+			return
+		}
+		handler(cancel, entry)
+	})
+}
+
+func (a *apiClient) streamAppLogs(ctx context.Context, appID string, opts *logStreamOpts, handler logHandler) error {
+	return a.doStream(ctx, "apps/"+appID+"/logs", opts, handler)
+}
+
+// doStream calls handler for each entry of the log stream at path. In follow
+// mode a dropped connection is retried with backoff, resuming after the last
+// entry received, until logStreamRetries reconnects in a row bring nothing new.
+func (a *apiClient) doStream(ctx context.Context, path string, opts *logStreamOpts, handler logHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	params := *opts
+	cursor := &logCursor{}
+	retry := newStreamBackOff()
+	var timeErr error
+	for {
+		var received bool
+		err := a.subscribe(ctx, path, &params, func(msg *sse.Event) {
+			switch string(msg.Event) {
+			case "data":
+				entry := new(logEntry)
+				if err := json.Unmarshal(msg.Data, entry); err != nil || entry.Source == "ping" {
+					return
+				}
+				if entry.Time.IsZero() && params.needTime != "" {
+					timeErr = fmt.Errorf("Log stream has no timestamps, which %s requires", params.needTime)
+					cancel()
+					return
+				}
+				if !cursor.skip(entry) {
+					received = true
+					handler(cancel, entry)
+				}
+			case "EOF":
+				cancel()
+			}
+		})
+		if timeErr != nil {
+			return timeErr
+		}
+		if ctx.Err() != nil || !params.Follow || isPermanent(err) {
+			return streamError(ctx, err)
+		}
+		if err := cursor.resume(&params); err != nil {
+			return err
+		}
+		if received {
+			retry.Reset()
+		}
+		delay := retry.NextBackOff()
+		msg := "Log stream disconnected"
+		if err != nil {
+			msg += ": " + err.Error()
+		}
+		if delay == backoff.Stop {
+			return &exitError{ExitNetworkError, fmt.Errorf("%s, giving up after %d reconnects without new entries", msg, logStreamRetries)}
+		}
+		printStatus(fmt.Sprintf("%s, reconnecting in %s", msg, delay.Round(100*time.Millisecond)))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// subscribe makes a single connection to the log stream at path.
+func (a *apiClient) subscribe(ctx context.Context, path string, opts *logStreamOpts, handler func(msg *sse.Event)) error {
+	query, err := qstring.MarshalString(opts)
+	if err != nil {
+		return err
+	}
+	if query != "" {
+		path += "?" + query
+	}
+	client := sse.NewClient(a.apiURL + path)
+	client.Connection = a.httpClient
+	client.ReconnectStrategy = &backoff.StopBackOff{}
+	client.ResponseValidator = func(c *sse.Client, resp *http.Response) error {
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		defer resp.Body.Close()
+		apierr := &lade.APIError{Status: resp.StatusCode}
+		if body, err := ioutil.ReadAll(resp.Body); err == nil && len(body) > 0 {
+			json.Unmarshal(body, apierr)
+		}
+		return apierr
+	}
+	return client.SubscribeRawWithContext(ctx, handler)
+}
+
+// logCursor tracks the time of the last entry received so that a resumed
+// stream skips the entries already handled.
+type logCursor struct {
+	count int
+	since time.Time
+	last  time.Time
+	seen  map[string]bool
+}
+
+func (c *logCursor) skip(entry *logEntry) bool {
+	if entry.Time.IsZero() {
+		c.count++
+		return false
+	}
+	key := entry.Name + "\x00" + entry.Source + "\x00" + entry.Line
+	if entry.Time.Before(c.since) || entry.Time.Equal(c.since) && c.seen[key] {
+		return true
+	}
+	if entry.Time.After(c.last) {
+		c.last = entry.Time
+		c.seen = map[string]bool{}
+	}
+	if entry.Time.Equal(c.last) {
+		c.seen[key] = true
+	}
+	c.count++
+	return false
+}
+
+// resume moves opts past the entries handled before a reconnect. Without
+// timestamps there is no point to resume from, so only a stream that has not
+// handled any entries can start over.
+func (c *logCursor) resume(opts *logStreamOpts) error {
+	if c.count == 0 {
+		return nil
+	}
+	if c.last.IsZero() {
+		return errors.New("Log stream disconnected and has no timestamps to resume from")
+	}
+	c.since = c.last
+	opts.Since = c.last
+	opts.Tail = 0
+	return nil
+}
+
+func isPermanent(err error) bool {
+	var apiErr *lade.APIError
+	var retrieveErr *oauth2.RetrieveError
+	switch {
+	case errors.As(err, &retrieveErr):
+		return retrieveErr.Response == nil || retrieveErr.Response.StatusCode < http.StatusInternalServerError
+	case errors.As(err, &apiErr):
+		return apiErr.Status < http.StatusInternalServerError &&
+			apiErr.Status != http.StatusRequestTimeout && apiErr.Status != http.StatusTooManyRequests
+	}
+	return false
+}
+
+func streamError(ctx context.Context, err error) error {
+	var e *oauth2.RetrieveError
+	switch {
+	case errors.As(err, &e):
+		lines := strings.SplitN(e.Error(), "\n", 2)
+		return errors.New(lines[0])
+	case ctx.Err() != nil && errors.Is(err, context.Canceled):
+		return nil
+	}
+	return err
+}
+
+// streamAll runs stream for every app at once and returns the first error,
+// stopping the other streams.
+func streamAll(appNames []string, stream func(ctx context.Context, appName string) error) error {
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+	errs := make(chan error, len(appNames))
+	var wg sync.WaitGroup
+	for _, appName := range appNames {
+		wg.Add(1)
+		go func(appName string) {
+			defer wg.Done()
+			if err := stream(ctx, appName); err != nil && ctx.Err() == nil {
+				errs <- fmt.Errorf("%s: %w", appName, err)
+				cancel()
+			}
+		}(appName)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func newLogMerger(window time.Duration, handler logHandler) *logMerger {
+	m := &logMerger{handler: handler, window: window, done: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(window / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.flush(false)
+			case <-m.done:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+func (m *logMerger) Add(cancel context.CancelFunc, entry *logEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, &mergedEntry{logEntry: entry, cancel: cancel, arrived: time.Now()})
+}
+
+// Close stops the merger and prints the entries still held.
+func (m *logMerger) Close() {
+	close(m.done)
+	m.flush(true)
+}
+
+func (m *logMerger) flush(all bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		return m.entries[i].Time.Before(m.entries[j].Time)
+	})
+	now := time.Now()
+	i := 0
+	for ; i < len(m.entries); i++ {
+		entry := m.entries[i]
+		if !all && now.Sub(entry.arrived) < m.window {
+			break
+		}
+		m.handler(entry.cancel, entry.logEntry)
+	}
+	m.entries = m.entries[i:]
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lade-io/go-lade"
+	"gopkg.in/cenkalti/backoff.v1"
+)
+
+func newTestEntry(name, line string, t time.Time) *logEntry {
+	return &logEntry{LogEntry: lade.LogEntry{Name: name, Source: "stdout", Line: line}, Time: t}
+}
+
+func TestLogCursor(t *testing.T) {
+	start := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	cursor := &logCursor{}
+	for _, entry := range []*logEntry{
+		newTestEntry("web.1", "one", start),
+		newTestEntry("web.1", "two", start.Add(time.Second)),
+		newTestEntry("web.2", "two", start.Add(time.Second)),
+	} {
+		if cursor.skip(entry) {
+			t.Fatalf("skipped %q before a reconnect", entry.Line)
+		}
+	}
+	opts := &logStreamOpts{Follow: true, Tail: 100}
+	if err := cursor.resume(opts); err != nil {
+		t.Fatal(err)
+	}
+	if !opts.Since.Equal(start.Add(time.Second)) || opts.Tail != 0 {
+		t.Fatalf("got since %s and tail %d, want the last entry time and no tail", opts.Since, opts.Tail)
+	}
+	// The resumed stream repeats the entries at the since time.
+	tests := []struct {
+		entry *logEntry
+		skip  bool
+	}{
+		{newTestEntry("web.1", "one", start), true},
+		{newTestEntry("web.1", "two", start.Add(time.Second)), true},
+		{newTestEntry("web.2", "two", start.Add(time.Second)), true},
+		{newTestEntry("web.3", "two", start.Add(time.Second)), false},
+		{newTestEntry("web.1", "three", start.Add(2*time.Second)), false},
+		{newTestEntry("web.1", "three", start.Add(2*time.Second)), false},
+	}
+	for _, tt := range tests {
+		if skip := cursor.skip(tt.entry); skip != tt.skip {
+			t.Errorf("skip(%s %s) = %v, want %v", tt.entry.Name, tt.entry.Line, skip, tt.skip)
+		}
+	}
+}
+
+func TestLogCursorNoTime(t *testing.T) {
+	cursor := &logCursor{}
+	opts := &logStreamOpts{Follow: true}
+	if err := cursor.resume(opts); err != nil {
+		t.Fatalf("got error %v before any entries", err)
+	}
+	cursor.skip(newTestEntry("web.1", "one", time.Time{}))
+	want := "Log stream disconnected and has no timestamps to resume from"
+	if err := cursor.resume(opts); err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+// logStreamServer answers each connection with the events returned by
+// respond, then closes the connection. Calls to respond hold mu.
+func logStreamServer(t *testing.T, mu *sync.Mutex, respond func(n int, r *http.Request) []string) *apiClient {
+	var conns int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns++
+		events := respond(conns, r)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprint(w, event)
+		}
+	}))
+	t.Cleanup(server.Close)
+	restore := newStreamBackOff
+	t.Cleanup(func() { newStreamBackOff = restore })
+	newStreamBackOff = func() backoff.BackOff {
+		return backoff.WithMaxTries(&backoff.ZeroBackOff{}, logStreamRetries)
+	}
+	return &apiClient{httpClient: server.Client(), apiURL: server.URL + apiVersion}
+}
+
+func dataEvent(line string, t time.Time) string {
+	return fmt.Sprintf("event: data\ndata: {\"name\": \"web.1\", \"source\": \"stdout\", \"line\": %q, \"time\": %q}\n\n",
+		line, t.Format(time.RFC3339Nano))
+}
+
+func TestStreamResume(t *testing.T) {
+	start := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var since []string
+	api := logStreamServer(t, &mu, func(n int, r *http.Request) []string {
+		since = append(since, r.URL.Query().Get("since"))
+		switch n {
+		case 1:
+			// The connection drops without the EOF event.
+			return []string{dataEvent("one", start), dataEvent("two", start.Add(time.Second))}
+		case 2:
+			return []string{dataEvent("two", start.Add(time.Second)), dataEvent("three", start.Add(2*time.Second))}
+		}
+		return []string{dataEvent("four", start.Add(3*time.Second)), "event: EOF\ndata: \n\n"}
+	})
+	var lines []string
+	err := api.streamAppLogs(context.Background(), "myapp", &logStreamOpts{Follow: true}, func(cancel context.CancelFunc, entry *logEntry) {
+		lines = append(lines, entry.Line)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"one", "two", "three", "four"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if since[0] != "" || since[1] == "" || since[2] == since[1] {
+		t.Errorf("got since %q, want none and then the last entry times", since)
+	}
+}
+
+func TestStreamRetries(t *testing.T) {
+	start := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var conns int
+	api := logStreamServer(t, &mu, func(n int, r *http.Request) []string {
+		conns = n
+		// Every connection repeats the same entry and drops.
+		return []string{dataEvent("one", start)}
+	})
+	var lines []string
+	err := api.streamAppLogs(context.Background(), "myapp", &logStreamOpts{Follow: true}, func(cancel context.CancelFunc, entry *logEntry) {
+		lines = append(lines, entry.Line)
+	})
+	want := fmt.Sprintf("Log stream disconnected, giving up after %d reconnects without new entries", logStreamRetries)
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+	if code := ExitCode(err); code != ExitNetworkError {
+		t.Errorf("got exit code %d, want %d", code, ExitNetworkError)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != logStreamRetries+1 || len(lines) != 1 {
+		t.Errorf("got %d connections and lines %q, want %d connections and one line", conns, lines, logStreamRetries+1)
+	}
+}
+
+func TestStreamNeedTime(t *testing.T) {
+	var mu sync.Mutex
+	api := logStreamServer(t, &mu, func(n int, r *http.Request) []string {
+		return []string{"event: data\ndata: {\"name\": \"web.1\", \"line\": \"started\"}\n\n"}
+	})
+	opts := &logStreamOpts{needTime: "--timestamps"}
+	err := api.streamAppLogs(context.Background(), "myapp", opts, func(cancel context.CancelFunc, entry *logEntry) {
+		t.Errorf("got entry %q without a time", entry.Line)
+	})
+	if want := "Log stream has no timestamps, which --timestamps requires"; err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
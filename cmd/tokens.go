@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/iancoleman/orderedmap"
+	"github.com/lade-io/go-lade"
+	"github.com/spf13/cobra"
+)
+
+type apiToken struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Token      string     `json:"token,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type tokenCreateOpts struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+var tokenColumns = []column{
+	{"NAME", true, func(v interface{}) interface{} { return v.(*apiToken).Name }},
+	{"ID", false, func(v interface{}) interface{} { return v.(*apiToken).ID }},
+	{"SCOPES", true, func(v interface{}) interface{} { return strings.Join(v.(*apiToken).Scopes, ", ") }},
+	{"EXPIRES", true, func(v interface{}) interface{} { return printTime(v.(*apiToken).ExpiresAt, "Never") }},
+	{"LAST USED", true, func(v interface{}) interface{} { return printTime(v.(*apiToken).LastUsedAt, "Never") }},
+	{"CREATED", true, func(v interface{}) interface{} { return humanize.Time(v.(*apiToken).CreatedAt) }},
+}
+
+var tokenScopes = []string{"app", "user"}
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Manage API tokens",
+}
+
+var tokensCreateCmd = func() *cobra.Command {
+	var expires time.Duration
+	opts := &tokenCreateOpts{}
+	cmd := &cobra.Command{
+		Use:   "create <token-name>",
+		Short: "Create an API token",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				opts.Name = args[0]
+			}
+			if expires > 0 {
+				expiresAt := time.Now().UTC().Add(expires)
+				opts.ExpiresAt = &expiresAt
+			}
+			return tokensCreateRun(api, opts)
+		},
+	}
+	cmd.Flags().DurationVarP(&expires, "expires", "e", 90*24*time.Hour, "Expire After (0 for never)")
+	cmd.Flags().StringSliceVarP(&opts.Scopes, "scope", "s", []string{"app"}, "Scopes (app, user)")
+	return cmd
+}()
+
+var tokensListCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return tokensListRun(api)
+		},
+	}
+	addColumnsFlag(cmd, tokenColumns)
+	return cmd
+}()
+
+var tokensRevokeCmd = &cobra.Command{
+	Use:   "revoke <token-name>",
+	Short: "Revoke an API token",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		api, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return tokensRevokeRun(api, name)
+	},
+}
+
+func init() {
+	tokensCmd.AddCommand(tokensCreateCmd)
+	tokensCmd.AddCommand(tokensListCmd)
+	tokensCmd.AddCommand(tokensRevokeCmd)
+}
+
+func (a *apiClient) createToken(opts *tokenCreateOpts) (token *apiToken, err error) {
+	token = new(apiToken)
+	err = a.doCreate("tokens", opts, token)
+	return
+}
+
+func (a *apiClient) deleteToken(token *apiToken) error {
+	return a.doDelete("tokens/" + strconv.Itoa(token.ID))
+}
+
+func (a *apiClient) listTokens() (tokens []*apiToken, err error) {
+	err = a.doGet("tokens", &tokens)
+	return
+}
+
+func tokensCreateRun(api *apiClient, opts *tokenCreateOpts) error {
+	if err := askInput("Token Name:", "", &opts.Name, validateName); err != nil {
+		return err
+	}
+	for _, scope := range opts.Scopes {
+		if !contains(tokenScopes, scope) {
+			return fmt.Errorf("Scope must be one of %s", strings.Join(tokenScopes, ", "))
+		}
+	}
+	token, err := api.createToken(opts)
+	if err != nil {
+		return err
+	}
+	return getPrinter().Print(token, func() {
+		fmt.Println(token.Token)
+		fmt.Println("Store this token now, it will not be shown again.")
+		fmt.Println("Use it by setting LADE_API_TOKEN in your environment.")
+	})
+}
+
+func tokensListRun(api *apiClient) error {
+	tokens, err := api.listTokens()
+	if err != nil {
+		return err
+	}
+	return printList(tokens, tokenColumns)
+}
+
+func tokensRevokeRun(api *apiClient, name string) error {
+	if err := askSelect("Token Name:", "", nil, getTokenOptions(api), &name); err != nil {
+		return err
+	}
+	tokens, err := api.listTokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Name != name {
+			continue
+		}
+		confirm, err := askApproval("Do you really want to revoke " + token.Name + "?")
+		if err != nil {
+			return err
+		}
+		if confirm {
+			err = api.deleteToken(token)
+		}
+		return err
+	}
+	return fmt.Errorf("Token not found %s", name)
+}
+
+func getTokenOptions(api *apiClient) optionsFunc {
+	return func(client *lade.Client) (*orderedmap.OrderedMap, error) {
+		tokens, err := api.listTokens()
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			return nil, errors.New("You have not created any tokens")
+		}
+		options := orderedmap.New()
+		for _, token := range tokens {
+			options.Set(token.Name, token.Name)
+		}
+		options.SortKeys(sort.Strings)
+		return options, nil
+	}
+}
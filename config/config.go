@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/jinzhu/configor"
@@ -12,33 +14,111 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-type Config struct {
+type Profile struct {
 	AccessToken  string    `yaml:"access_token,omitempty" env:"LADE_ACCESS_TOKEN"`
 	RefreshToken string    `yaml:"refresh_token,omitempty" env:"LADE_REFRESH_TOKEN"`
 	Expiry       time.Time `yaml:"expiry,omitempty" env:"LADE_EXPIRY"`
 	APIURL       string    `yaml:"api_url,omitempty" env:"LADE_API_URL"`
 	AuthURL      string    `yaml:"auth_url,omitempty" env:"LADE_AUTH_URL"`
 	TokenURL     string    `yaml:"token_url,omitempty" env:"LADE_TOKEN_URL"`
+	DeviceURL    string    `yaml:"device_url,omitempty" env:"LADE_DEVICE_URL"`
 }
 
-func (c *Config) GetToken() *oauth2.Token {
+func (p *Profile) setToken(token *oauth2.Token) {
+	p.AccessToken = token.AccessToken
+	p.RefreshToken = token.RefreshToken
+	p.Expiry = token.Expiry
+}
+
+func (p *Profile) token() *oauth2.Token {
 	return &oauth2.Token{
-		AccessToken:  c.AccessToken,
-		RefreshToken: c.RefreshToken,
-		Expiry:       c.Expiry,
+		AccessToken:  p.AccessToken,
+		RefreshToken: p.RefreshToken,
+		Expiry:       p.Expiry,
+	}
+}
+
+type Config struct {
+	Profile
+	APIToken    string
+	ProfileName string
+	Credentials string
+	Current     string
+	Profiles    map[string]*Profile
+	credentials string
+	store       CredentialStore
+	storeName   string
+}
+
+type fileConfig struct {
+	Profile     `yaml:",inline"`
+	Credentials string              `yaml:"credentials,omitempty"`
+	Store       string              `yaml:"credentials_store,omitempty"`
+	Current     string              `yaml:"profile,omitempty"`
+	Profiles    map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+func (c *Config) GetToken() *oauth2.Token {
+	return c.token()
+}
+
+func (c *Config) ProfileToken(name string) (*oauth2.Token, error) {
+	token, err := c.store.Load(name)
+	if err != nil || token == nil {
+		return new(oauth2.Token), err
 	}
+	return token, nil
+}
+
+func (c *Config) ProfileNames() []string {
+	names := []string{}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Config) RemoveProfile(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("Profile not found %s", name)
+	}
+	if err := c.store.Remove(name); err != nil {
+		return err
+	}
+	delete(c.Profiles, name)
+	if c.Current == name {
+		c.Current = ""
+	}
+	return writeConfig(c)
 }
 
 func (c *Config) StoreToken(token *oauth2.Token) error {
-	c.AccessToken = token.AccessToken
-	c.RefreshToken = token.RefreshToken
-	c.Expiry = token.Expiry
+	c.setToken(token)
+	if _, ok := c.Profiles[c.ProfileName]; !ok {
+		c.Profiles[c.ProfileName] = new(Profile)
+	}
+	if err := c.store.Store(c.ProfileName, token); err != nil {
+		return err
+	}
+	return writeConfig(c)
+}
+
+func (c *Config) UseProfile(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		c.Profiles[name] = new(Profile)
+	}
+	c.Current = name
 	return writeConfig(c)
 }
 
 const (
-	configDirName  = "lade"
-	configFileName = "config.yaml"
+	configDirName      = "lade"
+	configFileName     = "config.yaml"
+	defaultCredentials = "keyring"
+	defaultProfileName = "default"
+	// plaintextCredentials is where configs that record no store keep tokens.
+	plaintextCredentials = "plaintext"
 )
 
 func init() {
@@ -46,18 +126,66 @@ func init() {
 }
 
 func Load(conf *Config) (err error) {
-	_, configFile, err := configPaths()
+	_, configPath, err := configPaths()
 	if err != nil {
 		return err
 	}
-	if _, err = os.Stat(configFile); err == nil {
-		err = configor.Load(conf, configFile)
-	} else {
-		err = configor.Load(conf)
+	file := new(fileConfig)
+	data, err := ioutil.ReadFile(configPath)
+	if err == nil {
+		err = yaml.Unmarshal(data, file)
+	} else if errors.Is(err, os.ErrNotExist) {
+		err = nil
 	}
 	if err != nil {
 		return fmt.Errorf("Config error: %s", err)
 	}
+	if file.Profiles == nil {
+		file.Profiles = map[string]*Profile{}
+		if file.Profile != (Profile{}) {
+			file.Profiles[defaultProfileName] = &file.Profile
+		}
+	}
+	conf.APIToken = os.Getenv("LADE_API_TOKEN")
+	conf.credentials = os.Getenv("LADE_CREDENTIALS")
+	if conf.credentials == "" {
+		conf.credentials = file.Credentials
+	}
+	conf.Credentials = conf.credentials
+	if conf.Credentials == "" {
+		conf.Credentials = defaultCredentials
+	}
+	conf.Current = file.Current
+	conf.Profiles = file.Profiles
+	if conf.store, conf.storeName, err = openCredentialStore(conf); err != nil {
+		return err
+	}
+	if err = migrateTokens(conf, file); err != nil {
+		return err
+	}
+	if conf.ProfileName == "" {
+		conf.ProfileName = os.Getenv("LADE_PROFILE")
+	}
+	if conf.ProfileName == "" {
+		conf.ProfileName = conf.Current
+	}
+	if conf.ProfileName == "" {
+		conf.ProfileName = defaultProfileName
+	}
+	conf.Profile = Profile{}
+	if profile, ok := conf.Profiles[conf.ProfileName]; ok {
+		conf.Profile = *profile
+	}
+	token, err := conf.store.Load(conf.ProfileName)
+	if err != nil {
+		return err
+	}
+	if token != nil {
+		conf.setToken(token)
+	}
+	if err = configor.Load(&conf.Profile); err != nil {
+		return fmt.Errorf("Config error: %s", err)
+	}
 	return nil
 }
 
@@ -74,8 +202,64 @@ func configPaths() (string, string, error) {
 	return configDir, configFile, nil
 }
 
+// migrateTokens moves tokens into the store in use from the store recorded
+// in the config file, and from config.yaml when they are left there. Configs
+// written before the store was recorded keep tokens in their credentials.
+func migrateTokens(c *Config, file *fileConfig) error {
+	from := file.Store
+	if from == "" {
+		from = file.Credentials
+	}
+	if from == "" {
+		from = plaintextCredentials
+	}
+	sources := []string{from}
+	if from != plaintextCredentials {
+		sources = append(sources, plaintextCredentials)
+	}
+	migrated, failed := false, false
+	for _, source := range sources {
+		if source == c.storeName {
+			continue
+		}
+		store, err := newCredentialStore(c, source)
+		if err != nil {
+			// A fallback from the selected store has already been warned about.
+			if source != c.Credentials {
+				fmt.Fprintf(os.Stderr, "Warning: tokens cannot be moved from %s credentials: %s\n", source, err)
+			}
+			failed = true
+			continue
+		}
+		for _, name := range c.ProfileNames() {
+			token, err := store.Load(name)
+			if err != nil {
+				return err
+			}
+			if token == nil || token.AccessToken == "" && token.RefreshToken == "" {
+				continue
+			}
+			if err = c.store.Store(name, token); err != nil {
+				return err
+			}
+			if err = store.Remove(name); err != nil {
+				return err
+			}
+			migrated = true
+		}
+	}
+	if !migrated && (from == c.storeName || failed) {
+		return nil
+	}
+	return writeConfig(c)
+}
+
 func writeConfig(c *Config) error {
-	data, err := yaml.Marshal(c)
+	file := &fileConfig{Credentials: c.credentials, Current: c.Current, Profiles: c.Profiles}
+	if c.storeName != plaintextCredentials {
+		file.Store = c.storeName
+	}
+	data, err := yaml.Marshal(file)
 	if err != nil {
 		return err
 	}
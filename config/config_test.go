@@ -0,0 +1,139 @@
+package config
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
+)
+
+const profilesConfig = `profile: work
+profiles:
+  personal:
+    access_token: personal-access
+    refresh_token: personal-refresh
+  work:
+    access_token: work-access
+    refresh_token: work-refresh
+    api_url: https://api.example.com/
+`
+
+func writeConfigFile(t *testing.T, configFile, data string) {
+	t.Helper()
+	if err := ioutil.WriteFile(configFile, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readConfigFile(t *testing.T, configFile string) *fileConfig {
+	t.Helper()
+	file := new(fileConfig)
+	if err := yaml.Unmarshal([]byte(readFile(t, configFile)), file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		profile string
+		token   string
+		apiURL  string
+	}{
+		{name: "current profile", profile: "work", token: "work-access", apiURL: "https://api.example.com/"},
+		{name: "env profile", env: "personal", profile: "personal", token: "personal-access"},
+		{name: "flag over env", flag: "work", env: "personal", profile: "work", token: "work-access", apiURL: "https://api.example.com/"},
+		{name: "new profile", flag: "staging", profile: "staging"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := setupConfig(t, "plaintext", "")
+			writeConfigFile(t, configFile, profilesConfig)
+			t.Setenv("LADE_PROFILE", tt.env)
+			conf := &Config{ProfileName: tt.flag}
+			if err := Load(conf); err != nil {
+				t.Fatal(err)
+			}
+			if conf.ProfileName != tt.profile {
+				t.Errorf("got profile %s, want %s", conf.ProfileName, tt.profile)
+			}
+			if got := conf.GetToken().AccessToken; got != tt.token {
+				t.Errorf("got token %q, want %q", got, tt.token)
+			}
+			if conf.APIURL != tt.apiURL {
+				t.Errorf("got api url %q, want %q", conf.APIURL, tt.apiURL)
+			}
+		})
+	}
+}
+
+func TestLoadSingleProfile(t *testing.T) {
+	configFile := setupConfig(t, "plaintext", "")
+	writeConfigFile(t, configFile, "access_token: access\nrefresh_token: refresh\napi_url: https://api.example.com/\n")
+	conf := loadConfig(t)
+	if names := conf.ProfileNames(); !reflect.DeepEqual(names, []string{defaultProfileName}) {
+		t.Fatalf("got profiles %q, want only %s", names, defaultProfileName)
+	}
+	if conf.GetToken().AccessToken != "access" || conf.APIURL != "https://api.example.com/" {
+		t.Errorf("got token %q and api url %q from the single profile", conf.GetToken().AccessToken, conf.APIURL)
+	}
+	if err := conf.StoreToken(&oauth2.Token{AccessToken: "new", RefreshToken: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+	file := readConfigFile(t, configFile)
+	if file.AccessToken != "" || file.Profiles[defaultProfileName].AccessToken != "new" {
+		t.Errorf("got config %+v, want the token under the default profile only", file)
+	}
+	if file.Profiles[defaultProfileName].APIURL != "https://api.example.com/" {
+		t.Errorf("got api url %q, want it kept", file.Profiles[defaultProfileName].APIURL)
+	}
+}
+
+func TestStoreTokenProfile(t *testing.T) {
+	configFile := setupConfig(t, "plaintext", "")
+	writeConfigFile(t, configFile, profilesConfig)
+	t.Setenv("LADE_PROFILE", "personal")
+	if err := loadConfig(t).StoreToken(&oauth2.Token{AccessToken: "new", RefreshToken: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+	file := readConfigFile(t, configFile)
+	if got := file.Profiles["personal"].AccessToken; got != "new" {
+		t.Errorf("got personal token %q, want new", got)
+	}
+	if got := file.Profiles["work"].AccessToken; got != "work-access" {
+		t.Errorf("got work token %q, want it unchanged", got)
+	}
+	if file.Current != "work" {
+		t.Errorf("got current profile %q, want work", file.Current)
+	}
+}
+
+func TestUseAndRemoveProfile(t *testing.T) {
+	configFile := setupConfig(t, "plaintext", "")
+	writeConfigFile(t, configFile, profilesConfig)
+	conf := loadConfig(t)
+	if err := conf.UseProfile("personal"); err != nil {
+		t.Fatal(err)
+	}
+	if conf = loadConfig(t); conf.ProfileName != "personal" {
+		t.Fatalf("got profile %s after use, want personal", conf.ProfileName)
+	}
+	if err := conf.RemoveProfile("personal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conf.RemoveProfile("personal"); err == nil || err.Error() != "Profile not found personal" {
+		t.Errorf("got error %v removing a removed profile", err)
+	}
+	file := readConfigFile(t, configFile)
+	if _, ok := file.Profiles["personal"]; ok || file.Current != "" {
+		t.Errorf("got profiles %v and current %q, want personal removed", file.Profiles, file.Current)
+	}
+	if conf = loadConfig(t); conf.ProfileName != defaultProfileName {
+		t.Errorf("got profile %s after removing the current one, want %s", conf.ProfileName, defaultProfileName)
+	}
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+type CredentialStore interface {
+	Load(profile string) (*oauth2.Token, error)
+	Remove(profile string) error
+	Store(profile string, token *oauth2.Token) error
+}
+
+const (
+	credentialsFileName = "credentials.enc"
+	keyringService      = "lade"
+)
+
+var credentialStores = map[string]func(c *Config) (CredentialStore, error){
+	"file":      newFileStore,
+	"keyring":   newKeyringStore,
+	"plaintext": newPlaintextStore,
+}
+
+func newCredentialStore(c *Config, name string) (CredentialStore, error) {
+	fn, ok := credentialStores[name]
+	if !ok {
+		return nil, errors.New("Credentials must be one of file, keyring or plaintext")
+	}
+	return fn(c)
+}
+
+// openCredentialStore opens the store selected by c.Credentials and returns
+// its name. When the keyring is unavailable, as on headless machines, the
+// encrypted file is used instead. Without a store set in the config and
+// without LADE_PASSPHRASE, tokens are kept in the config file.
+func openCredentialStore(c *Config) (CredentialStore, string, error) {
+	store, err := newCredentialStore(c, c.Credentials)
+	if err == nil || c.Credentials != "keyring" {
+		return store, c.Credentials, err
+	}
+	store, fileErr := newCredentialStore(c, "file")
+	if fileErr != nil && c.credentials == "" {
+		// Tokens are not needed with an API token, so CI runs stay quiet.
+		if c.APIToken == "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s, and %s, keeping tokens in %s\n", err, fileErr, configFileName)
+		}
+		store, _ = newPlaintextStore(c)
+		return store, plaintextCredentials, nil
+	}
+	if fileErr != nil {
+		return nil, "", fmt.Errorf("%s, and %s", err, fileErr)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s, using %s instead\n", err, credentialsFileName)
+	return store, "file", nil
+}
+
+type keyringStore struct{}
+
+func newKeyringStore(c *Config) (CredentialStore, error) {
+	_, err := keyring.Get(keyringService, defaultProfileName)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("Keyring is unavailable: %s", err)
+	}
+	return keyringStore{}, nil
+}
+
+func (keyringStore) Load(profile string) (*oauth2.Token, error) {
+	secret, err := keyring.Get(keyringService, profile)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Keyring error: %s", err)
+	}
+	token := new(oauth2.Token)
+	return token, json.Unmarshal([]byte(secret), token)
+}
+
+func (keyringStore) Remove(profile string) error {
+	err := keyring.Delete(keyringService, profile)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("Keyring error: %s", err)
+	}
+	return nil
+}
+
+func (keyringStore) Store(profile string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err = keyring.Set(keyringService, profile, string(data)); err != nil {
+		return fmt.Errorf("Keyring error: %s", err)
+	}
+	return nil
+}
+
+type fileStore struct {
+	path       string
+	passphrase []byte
+}
+
+func newFileStore(c *Config) (CredentialStore, error) {
+	passphrase := os.Getenv("LADE_PASSPHRASE")
+	if passphrase == "" {
+		return nil, errors.New("LADE_PASSPHRASE is required to use file credentials")
+	}
+	configDir, _, err := configPaths()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(configDir, credentialsFileName)
+	return &fileStore{path: path, passphrase: []byte(passphrase)}, nil
+}
+
+func (f *fileStore) Load(profile string) (*oauth2.Token, error) {
+	tokens, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return tokens[profile], nil
+}
+
+func (f *fileStore) Remove(profile string) error {
+	tokens, err := f.read()
+	if err != nil {
+		return err
+	}
+	delete(tokens, profile)
+	return f.write(tokens)
+}
+
+func (f *fileStore) Store(profile string, token *oauth2.Token) error {
+	tokens, err := f.read()
+	if err != nil {
+		return err
+	}
+	tokens[profile] = token
+	return f.write(tokens)
+}
+
+func (f *fileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(f.passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (f *fileStore) read() (map[string]*oauth2.Token, error) {
+	tokens := map[string]*oauth2.Token{}
+	data, err := ioutil.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(data) < 16 {
+		return nil, errors.New("Credentials file is corrupt")
+	}
+	aead, err := f.cipher(data[:16])
+	if err != nil {
+		return nil, err
+	}
+	data = data[16:]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("Credentials file is corrupt")
+	}
+	nonce, data := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, errors.New("Credentials file cannot be decrypted, check LADE_PASSPHRASE")
+	}
+	return tokens, json.Unmarshal(plain, &tokens)
+}
+
+func (f *fileStore) write(tokens map[string]*oauth2.Token) error {
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+	aead, err := f.cipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	data := append(salt, nonce...)
+	data = aead.Seal(data, nonce, plain, nil)
+	if err = os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.path, data, 0600)
+}
+
+type plaintextStore struct {
+	conf *Config
+}
+
+func newPlaintextStore(c *Config) (CredentialStore, error) {
+	return &plaintextStore{conf: c}, nil
+}
+
+func (p *plaintextStore) Load(profile string) (*oauth2.Token, error) {
+	prof, ok := p.conf.Profiles[profile]
+	if !ok {
+		return nil, nil
+	}
+	return prof.token(), nil
+}
+
+func (p *plaintextStore) Remove(profile string) error {
+	if prof, ok := p.conf.Profiles[profile]; ok {
+		prof.setToken(new(oauth2.Token))
+	}
+	return nil
+}
+
+func (p *plaintextStore) Store(profile string, token *oauth2.Token) error {
+	prof, ok := p.conf.Profiles[profile]
+	if !ok {
+		prof = new(Profile)
+		p.conf.Profiles[profile] = prof
+	}
+	prof.setToken(token)
+	return nil
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+var errNoKeyring = errors.New("no secret service")
+
+// setupConfig points the config at an empty directory and returns the path
+// of the config file.
+func setupConfig(t *testing.T, credentials, passphrase string) string {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("LADE_CONFIG", configFile)
+	t.Setenv("LADE_CREDENTIALS", credentials)
+	t.Setenv("LADE_PASSPHRASE", passphrase)
+	t.Setenv("LADE_API_TOKEN", "")
+	return configFile
+}
+
+func loadConfig(t *testing.T) *Config {
+	t.Helper()
+	conf := new(Config)
+	if err := Load(conf); err != nil {
+		t.Fatal(err)
+	}
+	return conf
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCredentialStores(t *testing.T) {
+	tests := []struct {
+		credentials string
+		passphrase  string
+	}{
+		{"plaintext", ""},
+		{"file", "secret"},
+		{"keyring", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.credentials, func(t *testing.T) {
+			keyring.MockInit()
+			configFile := setupConfig(t, tt.credentials, tt.passphrase)
+			token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}
+			if err := loadConfig(t).StoreToken(token); err != nil {
+				t.Fatal(err)
+			}
+			conf := loadConfig(t)
+			if got := conf.GetToken(); got.AccessToken != "access" || got.RefreshToken != "refresh" {
+				t.Fatalf("got token %+v, want access and refresh tokens", got)
+			}
+			inConfig := strings.Contains(readFile(t, configFile), "access")
+			if want := tt.credentials == "plaintext"; inConfig != want {
+				t.Errorf("got token in config file %v, want %v", inConfig, want)
+			}
+			encrypted := readFile(t, filepath.Join(filepath.Dir(configFile), credentialsFileName))
+			if want := tt.credentials == "file"; (encrypted != "") != want || strings.Contains(encrypted, "access") {
+				t.Errorf("got encrypted file %q, want one %v", encrypted, want)
+			}
+			secret, _ := keyring.Get(keyringService, defaultProfileName)
+			if want := tt.credentials == "keyring"; strings.Contains(secret, "access") != want {
+				t.Errorf("got keyring secret %q, want one %v", secret, want)
+			}
+			if err := conf.RemoveProfile(defaultProfileName); err != nil {
+				t.Fatal(err)
+			}
+			if got := loadConfig(t).GetToken(); got.AccessToken != "" {
+				t.Errorf("got token %q after removing the profile", got.AccessToken)
+			}
+		})
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	setupConfig(t, "file", "secret")
+	if err := loadConfig(t).StoreToken(&oauth2.Token{AccessToken: "access"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LADE_PASSPHRASE", "wrong")
+	want := "Credentials file cannot be decrypted, check LADE_PASSPHRASE"
+	if err := Load(new(Config)); err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
+func TestDefaultCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		keyringErr  error
+		credentials string
+		passphrase  string
+		store       string
+		err         string
+	}{
+		{name: "keyring", store: "keyring"},
+		{name: "no keyring", keyringErr: errNoKeyring, passphrase: "secret", store: "file"},
+		{name: "no keyring or passphrase", keyringErr: errNoKeyring, store: "plaintext"},
+		{
+			name:        "keyring set without keyring or passphrase",
+			keyringErr:  errNoKeyring,
+			credentials: "keyring",
+			err:         "Keyring is unavailable: no secret service, and LADE_PASSPHRASE is required to use file credentials",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.keyringErr != nil {
+				keyring.MockInitWithError(tt.keyringErr)
+			} else {
+				keyring.MockInit()
+			}
+			setupConfig(t, tt.credentials, tt.passphrase)
+			conf := new(Config)
+			err := Load(conf)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conf.storeName != tt.store {
+				t.Errorf("got store %s, want %s", conf.storeName, tt.store)
+			}
+		})
+	}
+}
+
+func TestMigrateTokens(t *testing.T) {
+	keyring.MockInit()
+	configFile := setupConfig(t, "", "secret")
+	legacy := "access_token: access\nrefresh_token: refresh\n"
+	if err := ioutil.WriteFile(configFile, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	encrypted := &fileStore{path: filepath.Join(filepath.Dir(configFile), credentialsFileName), passphrase: []byte("secret")}
+	steps := []struct {
+		credentials string
+		store       string
+	}{
+		// Configs from before the keyring default keep tokens in plaintext.
+		{"", "keyring"},
+		{"file", "file"},
+		{"keyring", "keyring"},
+		{"plaintext", "plaintext"},
+	}
+	for _, step := range steps {
+		t.Setenv("LADE_CREDENTIALS", step.credentials)
+		conf := loadConfig(t)
+		if conf.storeName != step.store {
+			t.Fatalf("%s: got store %s, want %s", step.credentials, conf.storeName, step.store)
+		}
+		if got := conf.GetToken(); got.AccessToken != "access" || got.RefreshToken != "refresh" {
+			t.Fatalf("%s: got token %+v after migration", step.store, got)
+		}
+		stores := map[string]bool{}
+		if strings.Contains(readFile(t, configFile), "access") {
+			stores["plaintext"] = true
+		}
+		if token, err := encrypted.Load(defaultProfileName); err != nil {
+			t.Fatal(err)
+		} else if token != nil {
+			stores["file"] = true
+		}
+		if _, err := keyring.Get(keyringService, defaultProfileName); err == nil {
+			stores["keyring"] = true
+		}
+		if len(stores) != 1 || !stores[step.store] {
+			t.Errorf("%s: got tokens in %v, want only in %s", step.store, stores, step.store)
+		}
+	}
+}
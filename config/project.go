@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const projectFileName = ".lade"
+
+type Project struct {
+	App     string   `yaml:"app,omitempty"`
+	Dir     string   `yaml:"dir,omitempty"`
+	Include []string `yaml:"include,omitempty"`
+	Path    string   `yaml:"-"`
+}
+
+func (p *Project) IsEmpty() bool {
+	return p.App == "" && p.Dir == "" && len(p.Include) == 0
+}
+
+// Resolve returns path relative to the directory of the project file.
+func (p *Project) Resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(p.Path), path)
+}
+
+func (p *Project) Remove() error {
+	err := os.Remove(p.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (p *Project) Save() error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.Path, data, 0644)
+}
+
+func LoadProject(dir string) (*Project, error) {
+	project := new(Project)
+	for cur := dir; ; cur = filepath.Dir(cur) {
+		file := filepath.Join(cur, projectFileName)
+		data, err := ioutil.ReadFile(file)
+		if err == nil {
+			if err = yaml.Unmarshal(data, project); err != nil {
+				return nil, errors.New("Project error: " + err.Error())
+			}
+			project.Path = file
+			return project, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if cur == filepath.Dir(cur) {
+			break
+		}
+	}
+	project.Path = filepath.Join(projectRoot(dir), projectFileName)
+	return project, nil
+}
+
+func projectRoot(dir string) string {
+	for cur := dir; ; cur = filepath.Dir(cur) {
+		if _, err := os.Stat(filepath.Join(cur, ".git")); err == nil {
+			return cur
+		}
+		if cur == filepath.Dir(cur) {
+			return dir
+		}
+	}
+}
@@ -13,7 +13,7 @@ func init() {
 }
 
 func main() {
-	if err := cmd.RootCmd.Execute(); err != nil {
-		os.Exit(1)
+	if err := cmd.Execute(); err != nil {
+		os.Exit(cmd.ExitCode(err))
 	}
 }